@@ -0,0 +1,163 @@
+// Package audit keeps an append-only JSON Lines log of every follow and unfollow
+// sent to GitHub, so past bulk actions can be reviewed and reverted.
+package audit
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gh-mutual-follow/internal/xdg"
+)
+
+// Results recorded in Entry.Result.
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// Entry is one line of the audit log.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Session string    `json:"session"`           // identifies the run of the tool that made the call
+	Batch   string    `json:"batch"`             // groups the calls of one single or bulk action
+	Reverts string    `json:"reverts,omitempty"` // the batch or session this call undoes
+	Account string    `json:"account"`           // the authenticated user
+	Action  string    `json:"action"`            // follow or unfollow
+	Target  string    `json:"target"`            // the user followed or unfollowed
+	Result  string    `json:"result"`            // ResultOK or ResultError
+	Error   string    `json:"error,omitempty"`
+}
+
+// Filter selects log entries. Zero fields match everything.
+type Filter struct {
+	Since   time.Time // inclusive
+	Until   time.Time // exclusive
+	Action  string
+	Target  string
+	Session string
+}
+
+// Match reports whether e is selected by the filter.
+func (f Filter) Match(e Entry) bool {
+	switch {
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.Time.Before(f.Until):
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	case f.Target != "" && e.Target != f.Target:
+		return false
+	case f.Session != "" && e.Session != f.Session:
+		return false
+	}
+	return true
+}
+
+// Log is an audit log file. It is safe for concurrent use.
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLog returns the log stored at path. The file is created on the first Append.
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// DefaultPath returns the log location under the XDG state directory:
+// $XDG_STATE_HOME/gh-mutual-follow/audit.jsonl, or ~/.local/state/... when unset.
+func DefaultPath() (string, error) {
+	return xdg.StatePath("audit.jsonl")
+}
+
+// Path returns the location of the log file.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append adds e to the end of the log.
+func (l *Log) Append(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Read returns the entries selected by filter, oldest first. A missing log has no entries.
+func (l *Log) Read(filter Filter) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.Open(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", line, err)
+		}
+		if filter.Match(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// NewSessionID returns a new identifier for a run of the tool, sortable by start time.
+func NewSessionID() string {
+	return newID()
+}
+
+// NewBatchID returns a new identifier for a single or bulk action.
+func NewBatchID() string {
+	return newID()
+}
+
+// newID returns a random identifier prefixed with the current time.
+func newID() string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,243 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+)
+
+// fakeClient implements the calls the audit client makes; the rest of github.Client is not used.
+type fakeClient struct {
+	github.Client
+	failures map[string]error
+}
+
+func (f *fakeClient) GetUser(ctx context.Context) (string, error) { return "me", nil }
+func (f *fakeClient) Follow(ctx context.Context, user string) error {
+	return f.failures[user]
+}
+func (f *fakeClient) Unfollow(ctx context.Context, user string) error {
+	return f.failures[user]
+}
+
+func newTestLog(t *testing.T) *Log {
+	t.Helper()
+	return NewLog(filepath.Join(t.TempDir(), "state", "audit.jsonl"))
+}
+
+func TestClient_RecordsActions(t *testing.T) {
+	log := newTestLog(t)
+	client := NewClient(&fakeClient{failures: map[string]error{"ghost": errors.New("HTTP 404: Not Found")}}, log, "s1")
+	ctx := context.Background()
+
+	if err := client.Follow(ctx, "alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Unfollow(ctx, "ghost"); err == nil || err.Error() != "HTTP 404: Not Found" {
+		t.Fatalf("expected the client error to be returned, got %v", err)
+	}
+
+	entries, err := log.Read(Filter{})
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	first, second := entries[0], entries[1]
+	if first.Account != "me" || first.Session != "s1" || first.Action != "follow" || first.Target != "alice" || first.Result != ResultOK || first.Error != "" {
+		t.Errorf("unexpected first entry %+v", first)
+	}
+	if second.Action != "unfollow" || second.Target != "ghost" || second.Result != ResultError || second.Error != "HTTP 404: Not Found" {
+		t.Errorf("unexpected second entry %+v", second)
+	}
+	if first.Time.IsZero() || time.Since(first.Time) > time.Minute {
+		t.Errorf("unexpected timestamp %v", first.Time)
+	}
+
+	info, err := os.Stat(log.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the log to be private, got %v", info.Mode().Perm())
+	}
+}
+
+func TestClient_ReportsLogFailures(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(&fakeClient{}, NewLog(filepath.Join(blocker, "audit.jsonl")), "s1")
+
+	err := client.Follow(context.Background(), "alice")
+	if err == nil || !strings.Contains(err.Error(), "follow alice succeeded but was not logged") {
+		t.Errorf("expected a logging error, got %v", err)
+	}
+}
+
+func TestLog_Read(t *testing.T) {
+	log := newTestLog(t)
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 12, 0, 0, 0, time.UTC) }
+	for _, e := range []Entry{
+		{Time: day(1), Session: "a", Action: "follow", Target: "alice", Result: ResultOK},
+		{Time: day(2), Session: "a", Action: "unfollow", Target: "bob", Result: ResultOK},
+		{Time: day(3), Session: "b", Action: "unfollow", Target: "alice", Result: ResultError, Error: "boom"},
+	} {
+		if err := log.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{"All", Filter{}, []string{"alice", "bob", "alice"}},
+		{"Since", Filter{Since: day(2)}, []string{"bob", "alice"}},
+		{"Until", Filter{Until: day(2)}, []string{"alice"}},
+		{"Action", Filter{Action: "unfollow"}, []string{"bob", "alice"}},
+		{"Target", Filter{Target: "alice"}, []string{"alice", "alice"}},
+		{"Session", Filter{Session: "b"}, []string{"alice"}},
+		{"Combined", Filter{Action: "unfollow", Target: "alice", Since: day(1)}, []string{"alice"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := log.Read(tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var targets []string
+			for _, e := range entries {
+				targets = append(targets, e.Target)
+			}
+			if strings.Join(targets, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, targets)
+			}
+		})
+	}
+}
+
+func TestLog_ReadMissing(t *testing.T) {
+	entries, err := newTestLog(t).Read(Filter{})
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected an empty log, got %v, %v", entries, err)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+	path, err := DefaultPath()
+	if err != nil || path != "/tmp/state/gh-mutual-follow/audit.jsonl" {
+		t.Errorf("unexpected path %q, %v", path, err)
+	}
+}
+
+func TestLog_LastBatch(t *testing.T) {
+	log := newTestLog(t)
+	client := NewClient(&fakeClient{failures: map[string]error{"ghost": errors.New("HTTP 404")}}, log, "s1")
+	ctx := context.Background()
+
+	b1 := WithBatch(ctx, "b1")
+	client.Unfollow(b1, "alice")
+	client.Unfollow(b1, "bob")
+	client.Follow(WithBatch(ctx, "b2"), "ghost") // failed only, nothing to undo
+
+	batch, entries, err := log.LastBatch("me")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if batch != "b1" || len(entries) != 2 {
+		t.Fatalf("expected batch b1 with 2 entries, got %q %+v", batch, entries)
+	}
+
+	// Undoing b1 removes it from the stack.
+	client.Follow(WithReverts(WithBatch(ctx, "b3"), "b1"), "alice")
+	client.Follow(WithReverts(WithBatch(ctx, "b3"), "b1"), "bob")
+	if batch, _, _ := log.LastBatch("me"); batch != "" {
+		t.Errorf("expected nothing left to undo, got %q", batch)
+	}
+	if batch, _, _ := log.LastBatch("someone-else"); batch != "" {
+		t.Errorf("expected nothing to undo for another account, got %q", batch)
+	}
+}
+
+func TestLog_LastBatchAfterPartialUndo(t *testing.T) {
+	log := newTestLog(t)
+	fake := &fakeClient{}
+	client := NewClient(fake, log, "s1")
+	ctx := context.Background()
+
+	b1 := WithBatch(ctx, "b1")
+	client.Unfollow(b1, "alice")
+	client.Unfollow(b1, "carol")
+	fake.failures = map[string]error{"carol": errors.New("HTTP 500")}
+	undo := WithReverts(WithBatch(ctx, "b2"), "b1")
+	client.Follow(undo, "alice")
+	client.Follow(undo, "carol")
+
+	// carol was not followed back, so b1 stays on the stack with carol only.
+	batch, entries, err := log.LastBatch("me")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if batch != "b1" || len(entries) != 1 || entries[0].Target != "carol" {
+		t.Fatalf("expected batch b1 with carol only, got %q %+v", batch, entries)
+	}
+}
+
+func TestLog_Unreverted(t *testing.T) {
+	log := newTestLog(t)
+	client := NewClient(&fakeClient{failures: map[string]error{"carol": errors.New("HTTP 500")}}, log, "s1")
+	ctx := context.Background()
+
+	client.Unfollow(WithBatch(ctx, "b1"), "alice")
+	client.Unfollow(WithBatch(ctx, "b1"), "bob")
+	undo := WithReverts(WithBatch(ctx, "b2"), "b1")
+	client.Follow(undo, "alice")
+	client.Follow(WithBatch(ctx, "b3"), "carol")
+
+	entries, err := log.Read(Filter{Session: "s1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, err = log.Unreverted(entries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var targets []string
+	for _, e := range entries {
+		targets = append(targets, e.Action+" "+e.Target)
+	}
+	if expected := "unfollow bob,follow carol"; strings.Join(targets, ",") != expected {
+		t.Errorf("expected %s, got %v", expected, targets)
+	}
+}
+
+func TestRevert(t *testing.T) {
+	entries := []Entry{
+		{Action: "unfollow", Target: "alice", Result: ResultOK},
+		{Action: "follow", Target: "bob", Result: ResultOK},
+		{Action: "unfollow", Target: "carol", Result: ResultOK},
+		{Action: "follow", Target: "carol", Result: ResultOK}, // carol ends up where it started
+		{Action: "follow", Target: "ghost", Result: ResultError},
+	}
+
+	follow, unfollow, err := Revert(entries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(follow, ",") != "alice" || strings.Join(unfollow, ",") != "bob" {
+		t.Errorf("expected to follow [alice] and unfollow [bob], got %v and %v", follow, unfollow)
+	}
+}
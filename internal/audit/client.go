@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+)
+
+type contextKey int
+
+const (
+	batchKey contextKey = iota
+	revertsKey
+)
+
+// WithBatch returns a context whose follows and unfollows are logged as one batch.
+// Calls made without a batch are each logged as their own batch.
+func WithBatch(ctx context.Context, batch string) context.Context {
+	return context.WithValue(ctx, batchKey, batch)
+}
+
+// WithReverts returns a context whose follows and unfollows are logged as undoing
+// the given batch or session, which then can no longer be undone.
+func WithReverts(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, revertsKey, id)
+}
+
+// Client is a github.Client that records every Follow and Unfollow in a Log.
+// Reads are passed through unchanged.
+type Client struct {
+	github.Client
+	log     *Log
+	session string
+
+	mu      sync.Mutex
+	account string
+}
+
+// NewClient wraps client so that its follows and unfollows are logged under session.
+func NewClient(client github.Client, log *Log, session string) *Client {
+	return &Client{Client: client, log: log, session: session}
+}
+
+// Session returns the session ID written with every entry.
+func (c *Client) Session() string {
+	return c.session
+}
+
+func (c *Client) GetUser(ctx context.Context) (string, error) {
+	user, err := c.Client.GetUser(ctx)
+	if err == nil {
+		c.mu.Lock()
+		c.account = user
+		c.mu.Unlock()
+	}
+	return user, err
+}
+
+func (c *Client) Follow(ctx context.Context, user string) error {
+	return c.record(ctx, "follow", user, c.Client.Follow(ctx, user))
+}
+
+func (c *Client) Unfollow(ctx context.Context, user string) error {
+	return c.record(ctx, "unfollow", user, c.Client.Unfollow(ctx, user))
+}
+
+// record logs the outcome of an action and returns err. An action that succeeded
+// but could not be logged is reported as an error, so it is not lost silently.
+func (c *Client) record(ctx context.Context, action, target string, err error) error {
+	batch, _ := ctx.Value(batchKey).(string)
+	if batch == "" {
+		batch = NewBatchID()
+	}
+	reverts, _ := ctx.Value(revertsKey).(string)
+	e := Entry{
+		Time:    time.Now().UTC(),
+		Session: c.session,
+		Batch:   batch,
+		Reverts: reverts,
+		Account: c.accountName(ctx),
+		Action:  action,
+		Target:  target,
+		Result:  ResultOK,
+	}
+	if err != nil {
+		e.Result = ResultError
+		e.Error = err.Error()
+	}
+	if logErr := c.log.Append(e); logErr != nil {
+		if err != nil {
+			return fmt.Errorf("%w (and %v)", err, logErr)
+		}
+		return fmt.Errorf("%s %s succeeded but was not logged: %w", action, target, logErr)
+	}
+	return err
+}
+
+// accountName returns the authenticated user, looking it up if no GetUser call was made yet.
+func (c *Client) accountName(ctx context.Context) string {
+	c.mu.Lock()
+	account := c.account
+	c.mu.Unlock()
+	if account != "" {
+		return account
+	}
+	account, _ = c.GetUser(ctx)
+	return account
+}
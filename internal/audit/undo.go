@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LastBatch returns the ID and entries of the most recent batch of account that can
+// still be undone: it is not itself an undo, and at least one of its successful calls
+// has not been reverted (alone or as part of its session). The calls already reverted
+// are left out of the entries, so a partial undo can be retried.
+// It returns an empty ID when there is nothing to undo.
+func (l *Log) LastBatch(account string) (string, []Entry, error) {
+	entries, err := l.Read(Filter{})
+	if err != nil {
+		return "", nil, err
+	}
+
+	reverted := revertedTargets(entries)
+	var batch string
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Account != account || e.Reverts != "" || e.Result != ResultOK || reverted.has(e) {
+			continue
+		}
+		batch = e.Batch
+		break
+	}
+	if batch == "" {
+		return "", nil, nil
+	}
+
+	var result []Entry
+	for _, e := range entries {
+		if e.Batch == batch && !reverted.has(e) {
+			result = append(result, e)
+		}
+	}
+	return batch, result, nil
+}
+
+// Unreverted returns entries without the calls an undo has already reverted and
+// without the undo calls themselves, so that reverting what is left never repeats
+// or reverses a successful undo. A failed undo call leaves its target to be retried.
+func (l *Log) Unreverted(entries []Entry) ([]Entry, error) {
+	all, err := l.Read(Filter{})
+	if err != nil {
+		return nil, err
+	}
+
+	reverted := revertedTargets(all)
+	ids := make(map[string]bool)
+	for _, e := range entries {
+		ids[e.Batch], ids[e.Session] = true, true
+	}
+	var result []Entry
+	for _, e := range entries {
+		if (e.Reverts != "" && ids[e.Reverts]) || reverted.has(e) {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// reverted holds the targets successfully reverted by undo calls, by the batch or
+// session they undid.
+type reverted map[string]map[string]bool
+
+func revertedTargets(entries []Entry) reverted {
+	r := make(reverted)
+	for _, e := range entries {
+		if e.Reverts == "" || e.Result != ResultOK {
+			continue
+		}
+		if r[e.Reverts] == nil {
+			r[e.Reverts] = make(map[string]bool)
+		}
+		r[e.Reverts][e.Target] = true
+	}
+	return r
+}
+
+// has reports whether the call of e has been reverted, alone or with its session.
+func (r reverted) has(e Entry) bool {
+	return r[e.Batch][e.Target] || r[e.Session][e.Target]
+}
+
+// Revert returns the follows and unfollows that restore every target of entries to
+// its state before the first successful call. Targets back in their original state
+// are left alone. Both lists are sorted.
+func Revert(entries []Entry) (follow, unfollow []string, err error) {
+	type change struct{ first, last string }
+	changes := make(map[string]*change)
+	for _, e := range entries {
+		if e.Result != ResultOK {
+			continue
+		}
+		if e.Action != "follow" && e.Action != "unfollow" {
+			return nil, nil, fmt.Errorf("cannot revert unknown action %q for %s", e.Action, e.Target)
+		}
+		if c, ok := changes[e.Target]; ok {
+			c.last = e.Action
+		} else {
+			changes[e.Target] = &change{first: e.Action, last: e.Action}
+		}
+	}
+
+	for target, c := range changes {
+		wasFollowing := c.first == "unfollow"
+		isFollowing := c.last == "follow"
+		switch {
+		case wasFollowing && !isFollowing:
+			follow = append(follow, target)
+		case !wasFollowing && isFollowing:
+			unfollow = append(unfollow, target)
+		}
+	}
+	sort.Strings(follow)
+	sort.Strings(unfollow)
+	return follow, unfollow, nil
+}
@@ -0,0 +1,228 @@
+// Package bulk follows or unfollows many users with bounded concurrency,
+// recording the outcome for every user. It is shared by the TUI and the
+// headless commands.
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+)
+
+// DefaultConcurrency is the number of workers used when Executor.Concurrency is not set.
+const DefaultConcurrency = 2
+
+// DefaultThrottle keeps bulk actions at or below 60 calls per minute, which stays
+// clear of GitHub's secondary rate limits for follow and unfollow.
+const DefaultThrottle = time.Second
+
+// ErrDuplicate is the skip reason of a login that appears again in the same run.
+var ErrDuplicate = errors.New("duplicate")
+
+// Action is the operation applied to every user.
+type Action string
+
+const (
+	Follow   Action = "follow"
+	Unfollow Action = "unfollow"
+)
+
+// Status is the outcome of an action for a single user.
+type Status int
+
+const (
+	Succeeded Status = iota
+	Failed
+	Skipped
+)
+
+func (s Status) String() string {
+	switch s {
+	case Succeeded:
+		return "succeeded"
+	case Failed:
+		return "failed"
+	default:
+		return "skipped"
+	}
+}
+
+// Result records what happened to one user.
+type Result struct {
+	Login  string
+	Status Status
+	Err    error // the failure or skip reason
+}
+
+// Progress is reported after each user has been processed.
+type Progress struct {
+	Result  Result // the user that was just processed
+	Done    int
+	Total   int
+	Elapsed time.Duration
+}
+
+// Percent returns the completed fraction between 0 and 1.
+func (p Progress) Percent() float64 {
+	if p.Total == 0 {
+		return 1
+	}
+	return float64(p.Done) / float64(p.Total)
+}
+
+// ETA estimates the time remaining from the average time per user so far.
+func (p Progress) ETA() time.Duration {
+	if p.Done == 0 {
+		return 0
+	}
+	return p.Elapsed / time.Duration(p.Done) * time.Duration(p.Total-p.Done)
+}
+
+// Report is the outcome of a bulk action. Results are in the order of the input logins.
+type Report struct {
+	Action  Action
+	Results []Result
+}
+
+// Count returns how many results have the given status.
+func (r Report) Count(status Status) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the logins whose action failed, in order.
+func (r Report) Failed() []string {
+	var logins []string
+	for _, res := range r.Results {
+		if res.Status == Failed {
+			logins = append(logins, res.Login)
+		}
+	}
+	return logins
+}
+
+// Executor runs bulk actions against a github.Client.
+type Executor struct {
+	Client github.Client
+	// Concurrency is the number of users processed in parallel. Values below 1 mean DefaultConcurrency.
+	Concurrency int
+	// Throttle is the minimum delay between the start of two calls, across all workers.
+	Throttle time.Duration
+
+	mu        sync.Mutex
+	nextStart time.Time
+}
+
+// NewExecutor creates an Executor with the default concurrency and throttle.
+func NewExecutor(client github.Client) *Executor {
+	return &Executor{Client: client, Concurrency: DefaultConcurrency, Throttle: DefaultThrottle}
+}
+
+// Run applies action to every login and returns the outcome for each of them.
+// onProgress, if not nil, is called after each user, one call at a time.
+// Duplicate logins, and users that have not started when ctx is cancelled, are skipped.
+func (e *Executor) Run(ctx context.Context, action Action, logins []string, onProgress func(Progress)) Report {
+	workers := e.Concurrency
+	if workers < 1 {
+		workers = DefaultConcurrency
+	}
+
+	results := make([]Result, len(logins))
+	jobs := make(chan int)
+	start := time.Now()
+	done := 0
+	var progressMu sync.Mutex
+	report := func(i int) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		done++
+		if onProgress != nil {
+			onProgress(Progress{Result: results[i], Done: done, Total: len(logins), Elapsed: time.Since(start)})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = e.apply(ctx, action, logins[i])
+				report(i)
+			}
+		}()
+	}
+
+	seen := make(map[string]bool, len(logins))
+	for i, login := range logins {
+		if seen[login] {
+			results[i] = Result{Login: login, Status: Skipped, Err: ErrDuplicate}
+			report(i)
+			continue
+		}
+		seen[login] = true
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return Report{Action: action, Results: results}
+}
+
+// apply performs action for a single user once the throttle allows it.
+func (e *Executor) apply(ctx context.Context, action Action, login string) Result {
+	if err := e.wait(ctx); err != nil {
+		return Result{Login: login, Status: Skipped, Err: fmt.Errorf("cancelled: %w", err)}
+	}
+
+	var err error
+	switch action {
+	case Follow:
+		err = e.Client.Follow(ctx, login)
+	case Unfollow:
+		err = e.Client.Unfollow(ctx, login)
+	default:
+		err = fmt.Errorf("unknown action %q", action)
+	}
+	if err != nil {
+		return Result{Login: login, Status: Failed, Err: err}
+	}
+	return Result{Login: login, Status: Succeeded}
+}
+
+// wait blocks until the next call slot, or until ctx is done.
+func (e *Executor) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if e.Throttle <= 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	now := time.Now()
+	slot := e.nextStart
+	if slot.Before(now) {
+		slot = now
+	}
+	e.nextStart = slot.Add(e.Throttle)
+	e.mu.Unlock()
+
+	t := time.NewTimer(time.Until(slot))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,211 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+)
+
+// fakeClient is a github.Client whose Follow and Unfollow are provided by the test.
+type fakeClient struct {
+	followFunc   func(ctx context.Context, user string) error
+	unfollowFunc func(ctx context.Context, user string) error
+}
+
+func (f *fakeClient) GetUser(ctx context.Context) (string, error) { return "me", nil }
+func (f *fakeClient) GetFollowing(ctx context.Context, user string) ([]string, error) {
+	return nil, nil
+}
+func (f *fakeClient) GetFollowers(ctx context.Context, user string) ([]string, error) {
+	return nil, nil
+}
+func (f *fakeClient) GetFollowingUsers(ctx context.Context, user string) ([]github.User, error) {
+	return nil, nil
+}
+func (f *fakeClient) GetFollowersUsers(ctx context.Context, user string) ([]github.User, error) {
+	return nil, nil
+}
+func (f *fakeClient) GetUserProfile(ctx context.Context, login string) (github.User, error) {
+	return github.User{Login: login}, nil
+}
+func (f *fakeClient) GetLastActivity(ctx context.Context, login string) (time.Time, error) {
+	return time.Time{}, nil
+}
+func (f *fakeClient) Follow(ctx context.Context, user string) error {
+	if f.followFunc != nil {
+		return f.followFunc(ctx, user)
+	}
+	return nil
+}
+func (f *fakeClient) Unfollow(ctx context.Context, user string) error {
+	if f.unfollowFunc != nil {
+		return f.unfollowFunc(ctx, user)
+	}
+	return nil
+}
+
+func TestRun_RecordsEveryResultInOrder(t *testing.T) {
+	client := &fakeClient{
+		unfollowFunc: func(ctx context.Context, user string) error {
+			if user == "bob" {
+				return errors.New("HTTP 404")
+			}
+			return nil
+		},
+	}
+	e := &Executor{Client: client, Concurrency: 3}
+
+	var progress []Progress
+	report := e.Run(context.Background(), Unfollow, []string{"alice", "bob", "carol", "alice"}, func(p Progress) {
+		progress = append(progress, p)
+	})
+
+	expected := []struct {
+		login  string
+		status Status
+	}{
+		{"alice", Succeeded},
+		{"bob", Failed},
+		{"carol", Succeeded},
+		{"alice", Skipped},
+	}
+	if len(report.Results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(report.Results))
+	}
+	for i, want := range expected {
+		got := report.Results[i]
+		if got.Login != want.login || got.Status != want.status {
+			t.Errorf("result %d: expected %s %s, got %s %s", i, want.login, want.status, got.Login, got.Status)
+		}
+	}
+	if err := report.Results[3].Err; !errors.Is(err, ErrDuplicate) {
+		t.Errorf("expected the repeated alice to be skipped as a duplicate, got %v", err)
+	}
+	if failed := report.Failed(); len(failed) != 1 || failed[0] != "bob" {
+		t.Errorf("expected failed [bob], got %v", failed)
+	}
+	if report.Count(Succeeded) != 2 || report.Count(Skipped) != 1 {
+		t.Errorf("unexpected counts: %d succeeded, %d skipped", report.Count(Succeeded), report.Count(Skipped))
+	}
+
+	if len(progress) != 4 {
+		t.Fatalf("expected 4 progress reports, got %d", len(progress))
+	}
+	for i, p := range progress {
+		if p.Done != i+1 || p.Total != 4 {
+			t.Errorf("progress %d: expected %d/4, got %d/%d", i, i+1, p.Done, p.Total)
+		}
+	}
+}
+
+func TestRun_BoundsConcurrency(t *testing.T) {
+	const workers = 3
+	var inFlight, maxInFlight atomic.Int32
+	arrived := make(chan struct{}, 10)
+	release := make(chan struct{})
+	client := &fakeClient{
+		followFunc: func(ctx context.Context, user string) error {
+			n := inFlight.Add(1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			arrived <- struct{}{}
+			<-release
+			inFlight.Add(-1)
+			return nil
+		},
+	}
+	e := &Executor{Client: client, Concurrency: workers}
+
+	var report Report
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		report = e.Run(context.Background(), Follow, []string{"a", "b", "c", "d", "e", "f", "g"}, nil)
+	}()
+
+	for i := 0; i < workers; i++ {
+		<-arrived
+	}
+	close(release)
+	wg.Wait()
+
+	if got := maxInFlight.Load(); got != workers {
+		t.Errorf("expected at most %d calls in flight, got %d", workers, got)
+	}
+	if report.Count(Succeeded) != 7 {
+		t.Errorf("expected 7 successes, got %d", report.Count(Succeeded))
+	}
+}
+
+func TestRun_CancelSkipsRemainingUsers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &fakeClient{
+		followFunc: func(ctx context.Context, user string) error {
+			if user == "b" {
+				cancel()
+			}
+			return nil
+		},
+	}
+	e := &Executor{Client: client, Concurrency: 1}
+
+	report := e.Run(ctx, Follow, []string{"a", "b", "c", "d"}, nil)
+
+	if report.Count(Succeeded) != 2 || report.Count(Skipped) != 2 {
+		t.Errorf("expected 2 succeeded and 2 skipped, got %+v", report.Results)
+	}
+	for _, r := range report.Results[2:] {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("expected %s to be skipped as cancelled, got %v", r.Login, r.Err)
+		}
+	}
+}
+
+func TestRun_Throttle(t *testing.T) {
+	e := &Executor{Client: &fakeClient{}, Concurrency: 3, Throttle: 20 * time.Millisecond}
+
+	start := time.Now()
+	report := e.Run(context.Background(), Follow, []string{"a", "b", "c"}, nil)
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected calls to be spaced by the throttle, finished in %v", elapsed)
+	}
+	if report.Count(Succeeded) != 3 {
+		t.Errorf("expected 3 successes, got %d", report.Count(Succeeded))
+	}
+}
+
+func TestProgress_ETA(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress Progress
+		percent  float64
+		eta      time.Duration
+	}{
+		{"Not started", Progress{Done: 0, Total: 10}, 0, 0},
+		{"Quarter done", Progress{Done: 5, Total: 20, Elapsed: 10 * time.Second}, 0.25, 30 * time.Second},
+		{"Finished", Progress{Done: 4, Total: 4, Elapsed: time.Second}, 1, 0},
+		{"Empty", Progress{}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.progress.Percent(); got != tt.percent {
+				t.Errorf("expected percent %v, got %v", tt.percent, got)
+			}
+			if got := tt.progress.ETA(); got != tt.eta {
+				t.Errorf("expected ETA %v, got %v", tt.eta, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+// Package cli implements the command line interface: the headless subcommands
+// used from scripts and CI, and the tui subcommand that starts the interactive UI.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"time"
+
+	"gh-mutual-follow/internal/audit"
+	"gh-mutual-follow/internal/bulk"
+	"gh-mutual-follow/internal/config"
+	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/history"
+	"gh-mutual-follow/internal/store"
+	"gh-mutual-follow/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK    = 0
+	ExitError = 1 // the command failed, or some users could not be processed
+	ExitUsage = 2 // the command line was invalid
+)
+
+const usage = `Usage: gh-mutual-follow [flags] <command> [args]
+
+Commands:
+  tui                                         Start the interactive UI (default)
+  list [-output FORMAT] CATEGORY              Print the users of a category: following-only,
+                                              followers-only, mutual or all. FORMAT is text
+                                              (default), json, csv, tsv, yaml or markdown
+  follow <user...>                            Follow the given users
+  unfollow <user...>                          Unfollow the given users
+  sync                                        Follow back followers and unfollow non-followers
+  plan [-out FILE]                            Write the changes sync would make to a plan file
+  apply FILE                                  Apply a plan file, unless relationships changed since
+  log [-since DATE] [-until DATE]             Print the audit log of past follows and unfollows,
+      [-action A] [-target USER]              optionally filtered; DATE is YYYY-MM-DD or RFC 3339
+      [-session ID] [-output text|json]
+  revert SESSION                              Undo every follow and unfollow of an audit session
+  diff [-since 7d] [-output text|json]        Show new and lost followers and follows since a
+                                              duration ago (30m, 12h, 7d, 2w) or a date
+  query longtime-followers [-min-age 90d]     Print the followers who have followed for at least
+                                              a duration, according to the local store
+  query followed-back [-within 7d]            Print the followers followed back within a duration
+
+Users on the allowlist of the config file are never unfollowed, and users on its
+denylist never followed, by any command: see $XDG_CONFIG_HOME/gh-mutual-follow/config.yaml.
+
+Flags:
+`
+
+// errUsage marks errors caused by an invalid command line.
+var errUsage = errors.New("usage error")
+
+// errFlagsReported marks invalid subcommand flags, already reported by the flag package.
+var errFlagsReported = errors.New("invalid flags")
+
+// usageErrorf returns an error that makes Run exit with ExitUsage.
+func usageErrorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+// App holds the dependencies of the command line interface.
+type App struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// NewClient builds the GitHub client for the selected backend.
+	NewClient func(backend string, opts ...github.Option) (github.Client, error)
+	// RunTUI runs the interactive UI until it exits.
+	RunTUI func(client github.Client, events *TUIEvents, opts ...tui.Option) error
+	// AuditLog records every follow and unfollow. Nil disables auditing.
+	AuditLog *audit.Log
+	// History stores snapshots of the relationships on every fetch. Nil disables it.
+	History history.Store
+	// Store keeps the users and relationships seen on every fetch, for queries and
+	// for starting the TUI from cached data. Nil disables it.
+	Store store.Store
+	// Config holds the allowlist and denylist honoured by every command. Nil disables them.
+	Config *config.File
+
+	backend      string
+	concurrency  int
+	throttle     time.Duration
+	cacheTTL     time.Duration
+	dryRun       bool
+	confirm      bool
+	session      string // audit session ID of this run
+	sessionShown bool   // the session ID has been printed
+}
+
+// Run executes the command line args (without the program name) and returns the exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	app := &App{Stdout: stdout, Stderr: stderr, NewClient: NewClient, RunTUI: RunTUI}
+	if path, err := audit.DefaultPath(); err == nil {
+		app.AuditLog = audit.NewLog(path)
+	}
+	if path, err := config.DefaultPath(); err == nil {
+		app.Config = config.NewFile(path)
+	}
+	if s := openStore(stderr); s != nil {
+		defer s.Close()
+		app.Store, app.History = s, s
+	} else if path, err := history.DefaultPath(); err == nil {
+		app.History = history.NewLog(path)
+	}
+	return app.Run(args)
+}
+
+// openStore opens the local store at its default location, importing the snapshot
+// log written by earlier versions into a new store. Failures are reported on stderr
+// and leave the store disabled.
+func openStore(stderr io.Writer) *store.SQLite {
+	path, err := store.DefaultPath()
+	if err != nil {
+		return nil
+	}
+	s, err := store.OpenSQLite(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "gh-mutual-follow: %v\n", err)
+		return nil
+	}
+	if logPath, err := history.DefaultPath(); err == nil {
+		if _, err := store.ImportLog(s, history.NewLog(logPath)); err != nil {
+			fmt.Fprintf(stderr, "gh-mutual-follow: %v\n", err)
+		}
+	}
+	return s
+}
+
+// Run executes the command line args (without the program name) and returns the exit code.
+func (a *App) Run(args []string) int {
+	a.session, a.sessionShown = "", false
+	fs := flag.NewFlagSet("gh-mutual-follow", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	fs.Usage = func() {
+		fmt.Fprint(a.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&a.backend, "backend", "auto", "GitHub backend: auto, gh, rest or graphql")
+	fs.IntVar(&a.concurrency, "concurrency", bulk.DefaultConcurrency, "number of users followed or unfollowed in parallel")
+	fs.DurationVar(&a.throttle, "throttle", bulk.DefaultThrottle, "minimum delay between two follow or unfollow calls")
+	fs.DurationVar(&a.cacheTTL, "cache-ttl", github.DefaultCacheTTL, "how long the TUI reuses fetched lists before revalidating them in the background")
+	fs.BoolVar(&a.dryRun, "dry-run", false, "simulate follow and unfollow without changing anything on GitHub")
+	fs.BoolVar(&a.confirm, "confirm", true, "ask for confirmation before unfollowing or running a bulk action in the TUI")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	command, rest := "tui", fs.Args()
+	if len(rest) > 0 {
+		command, rest = rest[0], rest[1:]
+	}
+
+	var err error
+	switch command {
+	case "tui":
+		err = a.runTUI(rest)
+	case "list":
+		err = a.runList(ctx, rest)
+	case "follow":
+		err = a.runFollow(ctx, bulk.Follow, rest)
+	case "unfollow":
+		err = a.runFollow(ctx, bulk.Unfollow, rest)
+	case "sync":
+		err = a.runSync(ctx, rest)
+	case "plan":
+		err = a.runPlan(ctx, rest)
+	case "apply":
+		err = a.runApply(ctx, rest)
+	case "log":
+		err = a.runLog(rest)
+	case "revert":
+		err = a.runRevert(ctx, rest)
+	case "diff":
+		err = a.runDiff(ctx, rest)
+	case "query":
+		err = a.runQuery(ctx, rest)
+	default:
+		err = usageErrorf("unknown command %q", command)
+	}
+
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.Is(err, errFlagsReported):
+		return ExitUsage
+	}
+	if err != nil {
+		fmt.Fprintf(a.Stderr, "gh-mutual-follow: %v\n", err)
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(a.Stderr, "Run 'gh-mutual-follow -h' for usage.")
+			return ExitUsage
+		}
+		return ExitError
+	}
+	return ExitOK
+}
+
+// client builds the GitHub client for headless commands. Rate limit pauses are reported on stderr.
+func (a *App) client() (github.Client, error) {
+	client, err := a.NewClient(a.backend, github.WithRateLimitObserver(func(ev github.RateLimitEvent) {
+		if ev.Pause > 0 {
+			fmt.Fprintf(a.Stderr, "Rate limited, resuming in %s\n", ev.Pause.Round(time.Second))
+		}
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if a.dryRun {
+		fmt.Fprintln(a.Stderr, "DRY RUN: follow and unfollow are simulated, nothing is changed on GitHub")
+		return github.NewDryRunClient(client), nil
+	}
+	return a.audited(client), nil
+}
+
+// audited wraps client so that its follows and unfollows are written to the audit log.
+// Dry runs are not audited, since nothing reaches GitHub.
+func (a *App) audited(client github.Client) github.Client {
+	if a.AuditLog == nil {
+		return client
+	}
+	if a.session == "" {
+		a.session = audit.NewSessionID()
+	}
+	return audit.NewClient(client, a.AuditLog, a.session)
+}
+
+// executor builds the bulk executor configured by the global flags.
+func (a *App) executor(client github.Client) *bulk.Executor {
+	return &bulk.Executor{Client: client, Concurrency: a.concurrency, Throttle: a.throttle}
+}
+
+// runTUI starts the interactive UI.
+func (a *App) runTUI(args []string) error {
+	if len(args) > 0 {
+		return usageErrorf("tui takes no arguments")
+	}
+	events := &TUIEvents{}
+	client, err := a.NewClient(a.backend, github.WithRateLimitObserver(events.rateLimit))
+	if err != nil {
+		return err
+	}
+	// Lists are cached below the dry run and audit decorators, so that simulated
+	// follows stay out of the cache and audited ones update it.
+	// Revalidations still running when the TUI quits are cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cache := github.NewCachingClient(client, a.cacheTTL, github.WithRevalidateObserver(events.revalidated), github.WithBaseContext(ctx))
+	client = cache
+	opts := []tui.Option{
+		tui.WithBulkConcurrency(a.concurrency),
+		tui.WithBulkThrottle(a.throttle),
+		tui.WithConfirmation(a.confirm, a.confirm),
+		tui.WithCache(cache),
+	}
+	if a.Config != nil {
+		lists, err := a.Config.Load()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, tui.WithConfig(a.Config, lists))
+	}
+	if host := os.Getenv("GH_HOST"); host != "" {
+		opts = append(opts, tui.WithWebHost(host))
+	}
+	if a.dryRun {
+		client = github.NewDryRunClient(client)
+		opts = append(opts, tui.WithDryRun())
+	} else {
+		if a.AuditLog != nil {
+			client = a.audited(client)
+			opts = append(opts, tui.WithAuditLog(a.AuditLog))
+		}
+		if a.Store != nil {
+			opts = append(opts, tui.WithStore(a.Store))
+		} else if a.History != nil {
+			opts = append(opts, tui.WithHistory(a.History))
+		}
+	}
+	return a.RunTUI(client, events, opts...)
+}
+
+// TUIEvents forwards events from the GitHub client to a running TUI program.
+type TUIEvents struct {
+	program *tea.Program
+}
+
+func (e *TUIEvents) rateLimit(ev github.RateLimitEvent) {
+	if e.program != nil {
+		e.program.Send(tui.RateLimitMsg(ev))
+	}
+}
+
+func (e *TUIEvents) revalidated() {
+	if e.program != nil {
+		e.program.Send(tui.CacheRevalidatedMsg{})
+	}
+}
+
+// RunTUI runs the interactive UI with client until the user quits.
+func RunTUI(client github.Client, events *TUIEvents, opts ...tui.Option) error {
+	p := tea.NewProgram(tui.NewModelWithClient(client, opts...))
+	events.program = p
+	_, err := p.Run()
+	return err
+}
+
+// NewClient builds a client for the given backend. "auto" uses the gh CLI when
+// it is installed, and the REST API with a token from GH_TOKEN or GITHUB_TOKEN otherwise.
+func NewClient(backend string, opts ...github.Option) (github.Client, error) {
+	switch backend {
+	case "gh":
+		return github.NewClient(opts...), nil
+	case "rest", "graphql":
+		token := github.TokenFromEnv()
+		if token == "" {
+			return nil, fmt.Errorf("the %s backend needs a token in GH_TOKEN or GITHUB_TOKEN", backend)
+		}
+		if backend == "rest" {
+			return github.NewRESTClient(token, opts...), nil
+		}
+		return github.NewGraphQLClient(token, opts...), nil
+	case "auto":
+		if _, err := exec.LookPath("gh"); err != nil {
+			if token := github.TokenFromEnv(); token != "" {
+				return github.NewRESTClient(token, opts...), nil
+			}
+		}
+		return github.NewClient(opts...), nil
+	default:
+		return nil, usageErrorf("unknown backend %q", backend)
+	}
+}
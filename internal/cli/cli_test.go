@@ -0,0 +1,627 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gh-mutual-follow/internal/audit"
+	"gh-mutual-follow/internal/config"
+	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/history"
+	"gh-mutual-follow/internal/store"
+	"gh-mutual-follow/internal/tui"
+)
+
+// fakeClient is an in-memory github.Client. Follow and Unfollow update its state.
+type fakeClient struct {
+	mu         sync.Mutex
+	user       string
+	following  []string
+	followers  []string
+	failures   map[string]error // login -> error returned by Follow and Unfollow
+	followed   []string
+	unfollowed []string
+}
+
+func (f *fakeClient) GetUser(ctx context.Context) (string, error) { return f.user, nil }
+func (f *fakeClient) GetFollowing(ctx context.Context, user string) ([]string, error) {
+	return f.following, nil
+}
+func (f *fakeClient) GetFollowers(ctx context.Context, user string) ([]string, error) {
+	return f.followers, nil
+}
+func (f *fakeClient) GetFollowingUsers(ctx context.Context, user string) ([]github.User, error) {
+	return toUsers(f.following), nil
+}
+func (f *fakeClient) GetFollowersUsers(ctx context.Context, user string) ([]github.User, error) {
+	return toUsers(f.followers), nil
+}
+func (f *fakeClient) GetUserProfile(ctx context.Context, login string) (github.User, error) {
+	return github.User{Login: login}, nil
+}
+func (f *fakeClient) GetLastActivity(ctx context.Context, login string) (time.Time, error) {
+	return time.Time{}, nil
+}
+func (f *fakeClient) Follow(ctx context.Context, user string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failures[user]; err != nil {
+		return err
+	}
+	f.followed = append(f.followed, user)
+	return nil
+}
+func (f *fakeClient) Unfollow(ctx context.Context, user string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failures[user]; err != nil {
+		return err
+	}
+	f.unfollowed = append(f.unfollowed, user)
+	return nil
+}
+
+func toUsers(logins []string) []github.User {
+	users := make([]github.User, len(logins))
+	for i, l := range logins {
+		users[i] = github.User{Login: l}
+	}
+	return users
+}
+
+// newTestApp returns an App backed by client, with the throttle disabled.
+func newTestApp(client github.Client) (*App, *bytes.Buffer, *bytes.Buffer) {
+	var stdout, stderr bytes.Buffer
+	app := &App{
+		Stdout: &stdout,
+		Stderr: &stderr,
+		NewClient: func(backend string, opts ...github.Option) (github.Client, error) {
+			return client, nil
+		},
+		RunTUI: func(client github.Client, events *TUIEvents, opts ...tui.Option) error {
+			return errors.New("RunTUI not expected")
+		},
+	}
+	return app, &stdout, &stderr
+}
+
+func newRelationshipClient() *fakeClient {
+	return &fakeClient{
+		user:      "me",
+		following: []string{"carol", "alice", "bob"},
+		followers: []string{"bob", "dave", "erin"},
+	}
+}
+
+func TestRun_List(t *testing.T) {
+	tests := []struct {
+		category string
+		expected string
+	}{
+		{"following-only", "alice\ncarol\n"},
+		{"followers-only", "dave\nerin\n"},
+		{"mutual", "bob\n"},
+		{"all", "alice\ncarol\ndave\nerin\nbob\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			app, stdout, stderr := newTestApp(newRelationshipClient())
+
+			code := app.Run([]string{"-throttle=0", "list", tt.category})
+
+			if code != ExitOK {
+				t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+			}
+			if stdout.String() != tt.expected {
+				t.Errorf("expected output %q, got %q", tt.expected, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_ListOutput(t *testing.T) {
+	app, stdout, stderr := newTestApp(newRelationshipClient())
+
+	code := app.Run([]string{"list", "-output=csv", "mutual"})
+
+	if code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "login,category,") || !strings.HasPrefix(lines[1], "bob,mutual,") {
+		t.Errorf("unexpected CSV output %q", stdout.String())
+	}
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"Unknown command", []string{"frobnicate"}},
+		{"Unknown list category", []string{"list", "everyone"}},
+		{"List without category", []string{"list"}},
+		{"Unknown output format", []string{"list", "-output=xml", "mutual"}},
+		{"Follow without users", []string{"follow"}},
+		{"Apply without plan", []string{"apply"}},
+		{"Invalid log date", []string{"log", "-since", "yesterday"}},
+		{"Invalid diff period", []string{"diff", "-since", "fortnight"}},
+		{"Unknown query", []string{"query", "fans"}},
+		{"Invalid query period", []string{"query", "followed-back", "-within", "lately"}},
+		{"Unknown flag", []string{"-nope"}},
+		{"Unknown subcommand flag", []string{"list", "-nope", "mutual"}},
+		{"Unknown backend", []string{"-backend=svn", "list", "mutual"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _, stderr := newTestApp(newRelationshipClient())
+			app.NewClient = func(backend string, opts ...github.Option) (github.Client, error) {
+				return NewClient(backend, opts...)
+			}
+
+			if code := app.Run(tt.args); code != ExitUsage {
+				t.Errorf("expected exit code %d, got %d", ExitUsage, code)
+			}
+			if stderr.Len() == 0 {
+				t.Error("expected an error message on stderr")
+			}
+		})
+	}
+}
+
+func TestRun_SubcommandFlags(t *testing.T) {
+	app, _, stderr := newTestApp(newRelationshipClient())
+	if code := app.Run([]string{"list", "-h"}); code != ExitOK {
+		t.Errorf("expected exit code %d for -h, got %d", ExitOK, code)
+	}
+	if !strings.Contains(stderr.String(), "-output") || strings.Contains(stderr.String(), "usage error") {
+		t.Errorf("expected only the usage of list, got %q", stderr.String())
+	}
+
+	stderr.Reset()
+	if code := app.Run([]string{"list", "-nope", "mutual"}); code != ExitUsage {
+		t.Errorf("expected exit code %d for an unknown flag, got %d", ExitUsage, code)
+	}
+	if n := strings.Count(stderr.String(), "flag provided but not defined"); n != 1 {
+		t.Errorf("expected the flag error to be reported once, got %d times in %q", n, stderr.String())
+	}
+}
+
+func TestRun_Follow(t *testing.T) {
+	client := newRelationshipClient()
+	client.failures = map[string]error{"ghost": errors.New("HTTP 404: Not Found")}
+	app, stdout, stderr := newTestApp(client)
+
+	code := app.Run([]string{"-throttle=0", "-concurrency=1", "follow", "alice", "ghost", "bob"})
+
+	if code != ExitError {
+		t.Errorf("expected exit code %d when a user fails, got %d", ExitError, code)
+	}
+	if got := strings.Join(client.followed, ","); got != "alice,bob" {
+		t.Errorf("expected alice and bob to be followed, got %s", got)
+	}
+	if !strings.Contains(stdout.String(), "follow alice: ok") || !strings.Contains(stdout.String(), "follow bob: ok") {
+		t.Errorf("unexpected stdout %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "follow ghost: HTTP 404: Not Found") ||
+		!strings.Contains(stderr.String(), "failed to follow 1 of 3 users") {
+		t.Errorf("unexpected stderr %q", stderr.String())
+	}
+}
+
+func TestRun_Unfollow(t *testing.T) {
+	client := newRelationshipClient()
+	app, _, stderr := newTestApp(client)
+
+	if code := app.Run([]string{"-throttle=0", "unfollow", "alice"}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	if len(client.unfollowed) != 1 || client.unfollowed[0] != "alice" {
+		t.Errorf("expected alice to be unfollowed, got %v", client.unfollowed)
+	}
+}
+
+func TestRun_Sync(t *testing.T) {
+	tests := []struct {
+		name               string
+		args               []string
+		expectedFollowed   []string
+		expectedUnfollowed []string
+	}{
+		{
+			name:               "Both directions",
+			args:               []string{"-throttle=0", "sync"},
+			expectedFollowed:   []string{"dave", "erin"},
+			expectedUnfollowed: []string{"alice", "carol"},
+		},
+		{
+			name:             "Follow back only",
+			args:             []string{"-throttle=0", "sync", "-unfollow=false"},
+			expectedFollowed: []string{"dave", "erin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newRelationshipClient()
+			app, _, stderr := newTestApp(client)
+
+			if code := app.Run(tt.args); code != ExitOK {
+				t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+			}
+			sort.Strings(client.followed)
+			sort.Strings(client.unfollowed)
+			if strings.Join(client.followed, ",") != strings.Join(tt.expectedFollowed, ",") {
+				t.Errorf("expected followed %v, got %v", tt.expectedFollowed, client.followed)
+			}
+			if strings.Join(client.unfollowed, ",") != strings.Join(tt.expectedUnfollowed, ",") {
+				t.Errorf("expected unfollowed %v, got %v", tt.expectedUnfollowed, client.unfollowed)
+			}
+		})
+	}
+}
+
+func TestRun_ProtectedUsers(t *testing.T) {
+	newApp := func(t *testing.T) (*App, *fakeClient, *bytes.Buffer, *bytes.Buffer) {
+		t.Helper()
+		client := newRelationshipClient()
+		app, stdout, stderr := newTestApp(client)
+		app.Config = config.NewFile(filepath.Join(t.TempDir(), "config.yaml"))
+		if err := app.Config.Save(config.Config{Allow: []string{"alice"}, Deny: []string{"dave"}}); err != nil {
+			t.Fatalf("failed to save config: %v", err)
+		}
+		return app, client, stdout, stderr
+	}
+
+	t.Run("sync", func(t *testing.T) {
+		app, client, stdout, stderr := newApp(t)
+		if code := app.Run([]string{"-throttle=0", "sync"}); code != ExitOK {
+			t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+		}
+		if strings.Join(client.followed, ",") != "erin" || strings.Join(client.unfollowed, ",") != "carol" {
+			t.Errorf("expected the protected users to be left alone, got followed %v and unfollowed %v", client.followed, client.unfollowed)
+		}
+		for _, line := range []string{"follow dave: skipped (on the denylist)", "unfollow alice: skipped (on the allowlist)", "Following back 1 users", "Unfollowing 1 users"} {
+			if !strings.Contains(stdout.String(), line) {
+				t.Errorf("expected stdout to contain %q, got %q", line, stdout.String())
+			}
+		}
+	})
+
+	t.Run("plan and apply", func(t *testing.T) {
+		app, client, stdout, stderr := newApp(t)
+		path := filepath.Join(t.TempDir(), "plan.json")
+		if code := app.Run([]string{"plan", "-out", path}); code != ExitOK {
+			t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+		}
+		if strings.Contains(stdout.String(), "+ follow   dave") || strings.Contains(stdout.String(), "- unfollow alice") {
+			t.Errorf("expected the plan to leave out the protected users, got %q", stdout.String())
+		}
+
+		// Users protected after the plan was made are skipped too.
+		if err := app.Config.Save(config.Config{Allow: []string{"alice", "carol"}, Deny: []string{"dave"}}); err != nil {
+			t.Fatalf("failed to save config: %v", err)
+		}
+		if code := app.Run([]string{"-throttle=0", "apply", path}); code != ExitOK {
+			t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+		}
+		if strings.Join(client.followed, ",") != "erin" || len(client.unfollowed) != 0 {
+			t.Errorf("expected only erin to be followed, got followed %v and unfollowed %v", client.followed, client.unfollowed)
+		}
+	})
+
+	t.Run("explicit users", func(t *testing.T) {
+		app, client, stdout, stderr := newApp(t)
+		if code := app.Run([]string{"-throttle=0", "unfollow", "alice", "bob"}); code != ExitOK {
+			t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+		}
+		if code := app.Run([]string{"-throttle=0", "follow", "Dave"}); code != ExitOK {
+			t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+		}
+		if strings.Join(client.unfollowed, ",") != "bob" || len(client.followed) != 0 {
+			t.Errorf("expected only bob to be unfollowed, got followed %v and unfollowed %v", client.followed, client.unfollowed)
+		}
+		if !strings.Contains(stdout.String(), "follow Dave: skipped (on the denylist)") {
+			t.Errorf("unexpected stdout %q", stdout.String())
+		}
+	})
+}
+
+func TestRun_DryRun(t *testing.T) {
+	client := newRelationshipClient()
+	app, stdout, stderr := newTestApp(client)
+
+	if code := app.Run([]string{"-throttle=0", "-dry-run", "sync"}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	if len(client.followed)+len(client.unfollowed) != 0 {
+		t.Errorf("expected nothing to change, got followed %v and unfollowed %v", client.followed, client.unfollowed)
+	}
+	if !strings.Contains(stderr.String(), "DRY RUN") {
+		t.Errorf("expected a dry run notice, got %q", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "follow dave: ok") || !strings.Contains(stdout.String(), "unfollow alice: ok") {
+		t.Errorf("expected simulated results, got %q", stdout.String())
+	}
+}
+
+func TestRun_Diff(t *testing.T) {
+	client := newRelationshipClient()
+	app, stdout, stderr := newTestApp(client)
+	app.History = history.NewLog(filepath.Join(t.TempDir(), "snapshots.jsonl"))
+
+	if code := app.Run([]string{"diff"}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	if !strings.Contains(stdout.String(), "No earlier snapshot") {
+		t.Errorf("expected no snapshot to compare with, got %q", stdout.String())
+	}
+
+	// Move the saved snapshot ten days back and change the relationships since.
+	old, ok, err := app.History.Latest("me")
+	if err != nil || !ok {
+		t.Fatalf("expected the first run to save a snapshot (ok=%v err=%v)", ok, err)
+	}
+	app.History = history.NewLog(filepath.Join(t.TempDir(), "snapshots.jsonl"))
+	old.Time = time.Now().Add(-10 * 24 * time.Hour)
+	if err := app.History.Append(old); err != nil {
+		t.Fatalf("failed to append snapshot: %v", err)
+	}
+	client.following = []string{"alice", "bob", "frank"}
+	client.followers = []string{"bob", "erin", "grace"}
+	stdout.Reset()
+
+	if code := app.Run([]string{"diff", "-since", "7d"}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	for _, want := range []string{
+		"+ new follower      grace\n",
+		"- lost follower     dave\n",
+		"+ started following frank\n",
+		"- stopped following carol\n",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("diff does not contain %q:\n%s", want, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	if code := app.Run([]string{"diff", "-since", "2024-01-01", "-output", "json"}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	var d history.Diff
+	if err := json.Unmarshal(stdout.Bytes(), &d); err != nil {
+		t.Fatalf("failed to decode diff: %v", err)
+	}
+	if !reflect.DeepEqual(d.LostFollowers, []string{"dave"}) || !d.From.Equal(old.Time) {
+		t.Errorf("expected the changes since the old snapshot, got %+v", d)
+	}
+	if !strings.Contains(stderr.String(), "comparing with the oldest one") {
+		t.Errorf("expected a note that the history is shorter than asked, got %q", stderr.String())
+	}
+}
+
+func TestRun_Query(t *testing.T) {
+	app, stdout, stderr := newTestApp(newRelationshipClient())
+	s := store.NewMemory()
+	app.Store, app.History = s, s
+	old := history.NewSnapshot("me", []string{"alice", "carol"}, []string{"bob", "dave"})
+	old.Time = time.Now().Add(-100 * 24 * time.Hour)
+	if err := s.Append(old); err != nil {
+		t.Fatalf("failed to append snapshot: %v", err)
+	}
+
+	if code := app.Run([]string{"query", "longtime-followers"}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	if stdout.String() != "bob\ndave\n" {
+		t.Errorf("expected the followers of more than 90 days, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := app.Run([]string{"query", "followed-back", "-within", "7d"}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	if stdout.String() != "bob\n" {
+		t.Errorf("expected bob to be followed back, got %q", stdout.String())
+	}
+
+	// The fetches saved the profiles and a snapshot of the current lists.
+	if users, err := s.Users([]string{"erin"}); err != nil || len(users) != 1 {
+		t.Errorf("expected erin to be stored, got %v (err=%v)", users, err)
+	}
+	if latest, _, _ := s.Latest("me"); !reflect.DeepEqual(latest.Followers, []string{"bob", "dave", "erin"}) {
+		t.Errorf("expected the current followers to be saved, got %v", latest.Followers)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"36h", now.Add(-36 * time.Hour)},
+		{"2024-05-01T00:00:00Z", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.in, now)
+		if err != nil {
+			t.Errorf("parseSince(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	if _, err := parseSince("-3d", now); err == nil {
+		t.Error("expected an error for a negative period")
+	}
+}
+
+func TestRun_AuditLog(t *testing.T) {
+	client := newRelationshipClient()
+	client.failures = map[string]error{"ghost": errors.New("HTTP 404: Not Found")}
+	app, stdout, stderr := newTestApp(client)
+	app.AuditLog = audit.NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+
+	app.Run([]string{"-throttle=0", "follow", "alice", "ghost"})
+	app.Run([]string{"-throttle=0", "unfollow", "bob"})
+	app.Run([]string{"-throttle=0", "-dry-run", "unfollow", "carol"})
+	stdout.Reset()
+
+	if code := app.Run([]string{"log", "-action", "follow", "-since", "2000-01-01", "-output", "json"}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	var entries []audit.Entry
+	dec := json.NewDecoder(stdout)
+	for dec.More() {
+		var e audit.Entry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("failed to decode log output: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Target < entries[j].Target })
+	if len(entries) != 2 || entries[0].Target != "alice" || entries[1].Target != "ghost" {
+		t.Fatalf("expected the two follows, got %+v", entries)
+	}
+	if entries[0].Account != "me" || entries[0].Result != audit.ResultOK || entries[0].Session == "" {
+		t.Errorf("unexpected entry %+v", entries[0])
+	}
+	if entries[1].Result != audit.ResultError || entries[1].Error != "HTTP 404: Not Found" {
+		t.Errorf("unexpected entry %+v", entries[1])
+	}
+
+	stdout.Reset()
+	app.Run([]string{"log", "-target", "carol"})
+	if stdout.Len() != 0 {
+		t.Errorf("expected dry runs not to be logged, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	app.Run([]string{"log", "-until", "2000-01-01"})
+	if stdout.Len() != 0 {
+		t.Errorf("expected no entries before 2000, got %q", stdout.String())
+	}
+}
+
+func TestRun_Revert(t *testing.T) {
+	client := newRelationshipClient()
+	app, stdout, stderr := newTestApp(client)
+	app.AuditLog = audit.NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+
+	if code := app.Run([]string{"-throttle=0", "unfollow", "alice", "bob"}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	match := regexp.MustCompile(`Audit session (\S+)`).FindStringSubmatch(stderr.String())
+	if match == nil {
+		t.Fatalf("expected the session ID on stderr, got %q", stderr.String())
+	}
+	session := match[1]
+
+	// The denylist does not stop a revert from following alice back.
+	app.Config = config.NewFile(filepath.Join(t.TempDir(), "config.yaml"))
+	if err := app.Config.Save(config.Config{Deny: []string{"alice"}}); err != nil {
+		t.Fatal(err)
+	}
+	if code := app.Run([]string{"-throttle=0", "revert", session}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	sort.Strings(client.followed)
+	if strings.Join(client.followed, ",") != "alice,bob" {
+		t.Errorf("expected alice and bob to be followed again, got %v", client.followed)
+	}
+	if !strings.Contains(stdout.String(), "Reverting session "+session+": 2 to follow, 0 to unfollow") {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := app.Run([]string{"-throttle=0", "revert", session}); code != ExitError {
+		t.Errorf("expected a second revert to fail, got exit code %d", code)
+	}
+	if !strings.Contains(stderr.String(), "already been reverted") {
+		t.Errorf("unexpected stderr %q", stderr.String())
+	}
+	if code := app.Run([]string{"revert", "unknown"}); code != ExitError {
+		t.Errorf("expected reverting an unknown session to fail, got exit code %d", code)
+	}
+}
+
+func TestRun_PlanApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	client := newRelationshipClient()
+	app, stdout, stderr := newTestApp(client)
+
+	if code := app.Run([]string{"plan", "-out", path}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	for _, line := range []string{"+ follow   dave", "+ follow   erin", "- unfollow alice", "- unfollow carol"} {
+		if !strings.Contains(stdout.String(), line) {
+			t.Errorf("expected plan output to contain %q, got %q", line, stdout.String())
+		}
+	}
+	if len(client.followed)+len(client.unfollowed) != 0 {
+		t.Fatal("expected plan not to change anything")
+	}
+
+	if code := app.Run([]string{"-throttle=0", "apply", path}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	sort.Strings(client.followed)
+	sort.Strings(client.unfollowed)
+	if strings.Join(client.followed, ",") != "dave,erin" || strings.Join(client.unfollowed, ",") != "alice,carol" {
+		t.Errorf("expected the plan to be applied, got followed %v and unfollowed %v", client.followed, client.unfollowed)
+	}
+}
+
+func TestRun_ApplyRefusesDrift(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	client := newRelationshipClient()
+	app, _, stderr := newTestApp(client)
+
+	if code := app.Run([]string{"plan", "-out", path}); code != ExitOK {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+	client.followers = append(client.followers, "frank")
+
+	if code := app.Run([]string{"-throttle=0", "apply", path}); code != ExitError {
+		t.Fatalf("expected exit code %d, got %d", ExitError, code)
+	}
+	if !strings.Contains(stderr.String(), "new followers: frank") {
+		t.Errorf("expected the drift to be reported, got %q", stderr.String())
+	}
+	if len(client.followed)+len(client.unfollowed) != 0 {
+		t.Errorf("expected nothing to be applied, got followed %v and unfollowed %v", client.followed, client.unfollowed)
+	}
+}
+
+func TestRun_DefaultsToTUI(t *testing.T) {
+	app, _, _ := newTestApp(newRelationshipClient())
+	started := false
+	app.RunTUI = func(client github.Client, events *TUIEvents, opts ...tui.Option) error {
+		started = true
+		return nil
+	}
+
+	if code := app.Run(nil); code != ExitOK {
+		t.Errorf("expected exit code %d, got %d", ExitOK, code)
+	}
+	if !started {
+		t.Error("expected the TUI to be started")
+	}
+}
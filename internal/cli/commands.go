@@ -0,0 +1,393 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"gh-mutual-follow/internal/audit"
+	"gh-mutual-follow/internal/bulk"
+	"gh-mutual-follow/internal/config"
+	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/history"
+	"gh-mutual-follow/internal/output"
+	"gh-mutual-follow/internal/plan"
+)
+
+// Relationship categories accepted by the list command.
+const (
+	categoryFollowingOnly = "following-only"
+	categoryFollowersOnly = "followers-only"
+	categoryMutual        = "mutual"
+	categoryAll           = "all"
+)
+
+// relationships are the authenticated user's following and followers, split by category.
+type relationships struct {
+	username      string
+	onlyFollowing []string
+	onlyFollowers []string
+	mutual        []string
+	following     []string               // everyone followed, as returned by the API
+	followers     []string               // every follower, as returned by the API
+	profiles      map[string]github.User // every known user, by login
+}
+
+// category returns the sorted logins of a single category.
+func (r relationships) category(name string) []string {
+	switch name {
+	case categoryFollowingOnly:
+		return r.onlyFollowing
+	case categoryFollowersOnly:
+		return r.onlyFollowers
+	case categoryMutual:
+		return r.mutual
+	}
+	return nil
+}
+
+// records returns the output records of a category, or of every category for "all".
+func (r relationships) records(name string) []output.Record {
+	names := []string{name}
+	if name == categoryAll {
+		names = []string{categoryFollowingOnly, categoryFollowersOnly, categoryMutual}
+	}
+	var records []output.Record
+	for _, n := range names {
+		for _, login := range r.category(n) {
+			records = append(records, output.NewRecord(r.profiles[login], n))
+		}
+	}
+	return records
+}
+
+// fetchRelationships loads the following and followers of the authenticated user.
+func fetchRelationships(ctx context.Context, client github.Client) (relationships, error) {
+	username, err := client.GetUser(ctx)
+	if err != nil {
+		return relationships{}, fmt.Errorf("failed to get user: %w", err)
+	}
+	followingUsers, err := client.GetFollowingUsers(ctx, username)
+	if err != nil {
+		return relationships{}, fmt.Errorf("failed to get following: %w", err)
+	}
+	followersUsers, err := client.GetFollowersUsers(ctx, username)
+	if err != nil {
+		return relationships{}, fmt.Errorf("failed to get followers: %w", err)
+	}
+
+	r := relationships{username: username, profiles: make(map[string]github.User)}
+	for _, u := range append(followingUsers, followersUsers...) {
+		r.profiles[u.Login] = u
+	}
+	following, followers := github.Logins(followingUsers), github.Logins(followersUsers)
+	r.following, r.followers = following, followers
+	r.onlyFollowing, r.onlyFollowers, r.mutual = github.GetMutualFollowsData(username, following, followers)
+	sort.Strings(r.onlyFollowing)
+	sort.Strings(r.onlyFollowers)
+	sort.Strings(r.mutual)
+	return r, nil
+}
+
+// fetchRelationships fetches the relationships of the authenticated user and saves
+// a snapshot of them in the history. Failing to save is only reported: the command
+// can still do its job. Dry runs are not recorded, since their reads are simulated.
+func (a *App) fetchRelationships(ctx context.Context, client github.Client) (relationships, error) {
+	r, err := fetchRelationships(ctx, client)
+	if err != nil {
+		return r, err
+	}
+	a.recordSnapshot(r)
+	return r, nil
+}
+
+// recordSnapshot saves the following and followers of r in the history, and their
+// profiles in the store.
+func (a *App) recordSnapshot(r relationships) {
+	if a.dryRun {
+		return
+	}
+	if a.History != nil {
+		if err := a.History.Append(history.NewSnapshot(r.username, r.following, r.followers)); err != nil {
+			fmt.Fprintf(a.Stderr, "gh-mutual-follow: %v\n", err)
+		}
+	}
+	if a.Store != nil {
+		users := make([]github.User, 0, len(r.profiles))
+		for _, u := range r.profiles {
+			users = append(users, u)
+		}
+		if err := a.Store.SaveUsers(users); err != nil {
+			fmt.Fprintf(a.Stderr, "gh-mutual-follow: %v\n", err)
+		}
+	}
+}
+
+// runList prints the users of one relationship category, or of all of them, in the requested format.
+func (a *App) runList(ctx context.Context, args []string) error {
+	fs := newFlagSet("list", a)
+	format := fs.String("output", "text", "output format: "+strings.Join(output.Formats, ", "))
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageErrorf("list needs exactly one of following-only, followers-only, mutual or all")
+	}
+	category := fs.Arg(0)
+	switch category {
+	case categoryFollowingOnly, categoryFollowersOnly, categoryMutual, categoryAll:
+	default:
+		return usageErrorf("unknown list category %q", category)
+	}
+	if !slices.Contains(output.Formats, *format) {
+		return usageErrorf("unknown output format %q", *format)
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	r, err := a.fetchRelationships(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	return output.Write(a.Stdout, *format, r.records(category))
+}
+
+// runFollow follows or unfollows the users given on the command line.
+func (a *App) runFollow(ctx context.Context, action bulk.Action, args []string) error {
+	fs := newFlagSet(string(action), a)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("%s needs at least one user", action)
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	lists, err := a.lists()
+	if err != nil {
+		return err
+	}
+	return a.execute(ctx, client, action, a.unprotected(lists, action, fs.Args()))
+}
+
+// runSync follows back every follower that isn't followed and unfollows everyone who doesn't follow back.
+func (a *App) runSync(ctx context.Context, args []string) error {
+	fs := newFlagSet("sync", a)
+	follow := fs.Bool("follow", true, "follow back users who follow you")
+	unfollow := fs.Bool("unfollow", true, "unfollow users who don't follow you back")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("sync takes no arguments")
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	r, err := a.fetchRelationships(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	lists, err := a.lists()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if *follow {
+		logins := a.unprotected(lists, bulk.Follow, r.onlyFollowers)
+		fmt.Fprintf(a.Stdout, "Following back %d users\n", len(logins))
+		if err := a.execute(ctx, client, bulk.Follow, logins); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if *unfollow {
+		logins := a.unprotected(lists, bulk.Unfollow, r.onlyFollowing)
+		fmt.Fprintf(a.Stdout, "Unfollowing %d users\n", len(logins))
+		if err := a.execute(ctx, client, bulk.Unfollow, logins); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("sync incomplete: %v", errs)
+	}
+	return nil
+}
+
+// runPlan computes the changes sync would make and writes them to a plan file for review.
+func (a *App) runPlan(ctx context.Context, args []string) error {
+	fs := newFlagSet("plan", a)
+	out := fs.String("out", "plan.json", "plan file to write")
+	follow := fs.Bool("follow", true, "follow back users who follow you")
+	unfollow := fs.Bool("unfollow", true, "unfollow users who don't follow you back")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("plan takes no arguments")
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	r, err := a.fetchRelationships(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	lists, err := a.lists()
+	if err != nil {
+		return err
+	}
+	p := plan.New(r.username, r.following, r.followers, *follow, *unfollow)
+	p.Follow = a.unprotected(lists, bulk.Follow, p.Follow)
+	p.Unfollow = a.unprotected(lists, bulk.Unfollow, p.Unfollow)
+	if err := p.WriteDiff(a.Stdout); err != nil {
+		return err
+	}
+	if err := p.Save(*out); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Stdout, "\nSaved plan to %s. Run 'gh-mutual-follow apply %s' to apply it.\n", *out, *out)
+	return nil
+}
+
+// runApply executes a plan file, refusing to do so if the relationships changed since it was made.
+func (a *App) runApply(ctx context.Context, args []string) error {
+	fs := newFlagSet("apply", a)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageErrorf("apply needs exactly one plan file")
+	}
+	p, err := plan.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	r, err := a.fetchRelationships(ctx, client)
+	if err != nil {
+		return err
+	}
+	if err := p.Check(r.username, r.following, r.followers); err != nil {
+		return fmt.Errorf("refusing to apply %s: %w; run plan again", fs.Arg(0), err)
+	}
+	// Users protected since the plan was made are left out too.
+	lists, err := a.lists()
+	if err != nil {
+		return err
+	}
+	p.Follow = a.unprotected(lists, bulk.Follow, p.Follow)
+	p.Unfollow = a.unprotected(lists, bulk.Unfollow, p.Unfollow)
+
+	if err := p.WriteDiff(a.Stdout); err != nil {
+		return err
+	}
+	if p.Empty() {
+		return nil
+	}
+	fmt.Fprintln(a.Stdout)
+
+	var errs []error
+	if err := a.execute(ctx, client, bulk.Follow, p.Follow); err != nil {
+		errs = append(errs, err)
+	}
+	if err := a.execute(ctx, client, bulk.Unfollow, p.Unfollow); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("apply incomplete: %v", errs)
+	}
+	return nil
+}
+
+// execute runs a bulk action, printing each result. Failures are reported on stderr
+// and make the returned error non-nil. Callers leave out the users the config protects.
+func (a *App) execute(ctx context.Context, client github.Client, action bulk.Action, logins []string) error {
+	if len(logins) == 0 {
+		return nil
+	}
+	if a.session != "" && !a.sessionShown {
+		a.sessionShown = true
+		fmt.Fprintf(a.Stderr, "Audit session %s (undo with 'gh-mutual-follow revert %s')\n", a.session, a.session)
+	}
+	ctx = audit.WithBatch(ctx, audit.NewBatchID())
+	report := a.executor(client).Run(ctx, action, logins, func(p bulk.Progress) {
+		switch p.Result.Status {
+		case bulk.Succeeded:
+			fmt.Fprintf(a.Stdout, "%s %s: ok\n", action, p.Result.Login)
+		case bulk.Skipped:
+			fmt.Fprintf(a.Stdout, "%s %s: skipped (%v)\n", action, p.Result.Login, p.Result.Err)
+		case bulk.Failed:
+			fmt.Fprintf(a.Stderr, "%s %s: %v\n", action, p.Result.Login, p.Result.Err)
+		}
+	})
+	if failed := report.Count(bulk.Failed); failed > 0 {
+		return fmt.Errorf("failed to %s %d of %d users", action, failed, len(logins))
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s interrupted: %w", action, err)
+	}
+	return nil
+}
+
+// lists returns the allowlist and denylist of the config file, empty without one.
+func (a *App) lists() (config.Config, error) {
+	if a.Config == nil {
+		return config.Config{}, nil
+	}
+	return a.Config.Load()
+}
+
+// unprotected returns the logins action may run on, printing a skipped line for each
+// one lists protects. The result is never nil, so it can go into a plan file.
+func (a *App) unprotected(lists config.Config, action bulk.Action, logins []string) []string {
+	kept, protected := lists.Filter(action, logins)
+	reason := "on the denylist"
+	if action == bulk.Unfollow {
+		reason = "on the allowlist"
+	}
+	for _, login := range protected {
+		fmt.Fprintf(a.Stdout, "%s %s: skipped (%s)\n", action, login, reason)
+	}
+	if kept == nil {
+		kept = []string{}
+	}
+	return kept
+}
+
+// parseFlags parses the flags of a subcommand. The flag package has already written
+// any error and the usage to stderr, so the error returned only sets the exit code.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", errFlagsReported, err)
+}
+
+// newFlagSet creates the flag set of a subcommand, writing its usage to stderr.
+func newFlagSet(name string, a *App) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	return fs
+}
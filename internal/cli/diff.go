@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gh-mutual-follow/internal/history"
+)
+
+// runDiff prints how the relationships changed since a point in time, comparing the
+// current lists with the snapshot history.
+func (a *App) runDiff(ctx context.Context, args []string) error {
+	fs := newFlagSet("diff", a)
+	since := fs.String("since", "7d", "compare with the relationships at this time: a duration ago (30m, 12h, 7d, 2w) or a date")
+	format := fs.String("output", "text", "output format: text or json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("diff takes no arguments")
+	}
+	if *format != "text" && *format != "json" {
+		return usageErrorf("unknown output format %q", *format)
+	}
+	t, err := parseSince(*since, time.Now())
+	if err != nil {
+		return err
+	}
+	if a.History == nil {
+		return fmt.Errorf("the snapshot history is not available")
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	r, err := fetchRelationships(ctx, client)
+	if err != nil {
+		return err
+	}
+	// Look up the baseline before saving the current lists, so a first run does not
+	// compare the current lists with themselves.
+	baseline, ok, err := a.History.Baseline(r.username, t)
+	if err != nil {
+		return err
+	}
+	a.recordSnapshot(r)
+	if !ok {
+		fmt.Fprintln(a.Stdout, "No earlier snapshot to compare with. The current relationships were saved; run diff again later.")
+		return nil
+	}
+	if baseline.Time.After(t) {
+		fmt.Fprintf(a.Stderr, "No snapshot as old as %s, comparing with the oldest one\n", t.Local().Format("2006-01-02 15:04"))
+	}
+
+	d := history.Compare(baseline, history.NewSnapshot(r.username, r.following, r.followers))
+	if *format == "json" {
+		enc := json.NewEncoder(a.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(d); err != nil {
+			return fmt.Errorf("failed to write diff: %w", err)
+		}
+		return nil
+	}
+	return d.WriteDiff(a.Stdout)
+}
+
+// parseSince parses a point in time given as a duration before now, like 30m, 12h,
+// 7d or 2w, or as a date accepted by parseDate.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			if v, err := strconv.Atoi(n); err == nil && v >= 0 {
+				return now.Add(-time.Duration(v) * unit), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := parseDate(s, false); err == nil && !t.IsZero() {
+		return t, nil
+	}
+	return time.Time{}, usageErrorf("invalid time %q, expected a duration like 7d or a date", s)
+}
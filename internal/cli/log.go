@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"gh-mutual-follow/internal/audit"
+	"gh-mutual-follow/internal/bulk"
+)
+
+// runLog prints the audit log entries selected by the command line filters.
+func (a *App) runLog(args []string) error {
+	fs := newFlagSet("log", a)
+	since := fs.String("since", "", "only entries at or after this date (YYYY-MM-DD or RFC 3339)")
+	until := fs.String("until", "", "only entries before the end of this date (YYYY-MM-DD or RFC 3339)")
+	action := fs.String("action", "", "only follow or unfollow entries")
+	target := fs.String("target", "", "only entries for this user")
+	session := fs.String("session", "", "only entries of this session")
+	format := fs.String("output", "text", "output format: text or json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("log takes no arguments")
+	}
+	if *action != "" && *action != "follow" && *action != "unfollow" {
+		return usageErrorf("unknown action %q", *action)
+	}
+	if *format != "text" && *format != "json" {
+		return usageErrorf("unknown output format %q", *format)
+	}
+
+	filter := audit.Filter{Action: *action, Target: *target, Session: *session}
+	var err error
+	if filter.Since, err = parseDate(*since, false); err != nil {
+		return err
+	}
+	if filter.Until, err = parseDate(*until, true); err != nil {
+		return err
+	}
+
+	if a.AuditLog == nil {
+		return fmt.Errorf("the audit log is not available")
+	}
+	entries, err := a.AuditLog.Read(filter)
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(a.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return fmt.Errorf("failed to write entry: %w", err)
+			}
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		result := e.Result
+		if e.Error != "" {
+			result += ": " + e.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Time.Local().Format(time.DateTime), e.Session, e.Account, e.Action, e.Target, result)
+	}
+	return w.Flush()
+}
+
+// parseDate parses a YYYY-MM-DD date in local time or an RFC 3339 timestamp.
+// With endOfDay, a date means the end of that day, so -until includes it.
+func parseDate(s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		return time.Time{}, usageErrorf("invalid date %q, expected YYYY-MM-DD or RFC 3339", s)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// runRevert undoes every follow and unfollow of an audit session.
+func (a *App) runRevert(ctx context.Context, args []string) error {
+	fs := newFlagSet("revert", a)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageErrorf("revert needs exactly one session ID")
+	}
+	session := fs.Arg(0)
+	if a.AuditLog == nil {
+		return fmt.Errorf("the audit log is not available")
+	}
+
+	entries, err := a.AuditLog.Read(audit.Filter{Session: session})
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no audit entries for session %s", session)
+	}
+	if entries, err = a.AuditLog.Unreverted(entries); err != nil {
+		return err
+	} else if len(entries) == 0 {
+		return fmt.Errorf("session %s has already been reverted", session)
+	}
+	follow, unfollow, err := audit.Revert(entries)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	username, err := client.GetUser(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if account := entries[0].Account; account != username {
+		return fmt.Errorf("session %s was made by %s, but you are signed in as %s", session, account, username)
+	}
+
+	// A revert restores the accounts as they were, so the allowlist and denylist do
+	// not apply: skipping some would leave the session half reverted.
+	fmt.Fprintf(a.Stdout, "Reverting session %s: %d to follow, %d to unfollow\n", session, len(follow), len(unfollow))
+	ctx = audit.WithReverts(ctx, session)
+	var errs []error
+	if err := a.execute(ctx, client, bulk.Follow, follow); err != nil {
+		errs = append(errs, err)
+	}
+	if err := a.execute(ctx, client, bulk.Unfollow, unfollow); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("revert incomplete: %v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Queries accepted by the query command.
+const (
+	queryLongtimeFollowers = "longtime-followers"
+	queryFollowedBack      = "followed-back"
+)
+
+// runQuery answers a question about how the relationships evolved, from the local
+// store. The relationships are fetched and stored first, so the answer is current.
+func (a *App) runQuery(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("query needs %s or %s", queryLongtimeFollowers, queryFollowedBack)
+	}
+	name, args := args[0], args[1:]
+	fs := newFlagSet("query "+name, a)
+	var period *string
+	switch name {
+	case queryLongtimeFollowers:
+		period = fs.String("min-age", "90d", "print the followers following since at least this time: a duration ago (30m, 12h, 7d, 2w) or a date")
+	case queryFollowedBack:
+		period = fs.String("within", "7d", "print the followers followed back since this time: a duration ago (30m, 12h, 7d, 2w) or a date")
+	default:
+		return usageErrorf("unknown query %q", name)
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("query %s takes no arguments", name)
+	}
+	t, err := parseSince(*period, time.Now())
+	if err != nil {
+		return err
+	}
+	if a.Store == nil {
+		return fmt.Errorf("the local store is not available")
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	r, err := a.fetchRelationships(ctx, client)
+	if err != nil {
+		return err
+	}
+	// Relationships are only known from the first snapshot on: warn when the
+	// store does not go back far enough to answer for the whole period.
+	if oldest, ok, err := a.Store.Baseline(r.username, time.Time{}); err == nil && ok && oldest.Time.After(t) {
+		fmt.Fprintf(a.Stderr, "The store only goes back to %s\n", oldest.Time.Local().Format("2006-01-02 15:04"))
+	}
+
+	var logins []string
+	if name == queryLongtimeFollowers {
+		logins, err = a.Store.FollowersSince(r.username, t)
+	} else {
+		logins, err = a.Store.FollowedBackSince(r.username, t)
+	}
+	if err != nil {
+		return err
+	}
+	for _, login := range logins {
+		fmt.Fprintln(a.Stdout, login)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Package config reads and writes the user's configuration file: the allowlist of
+// accounts that are followed on purpose and must never be unfollowed in bulk, and
+// the denylist of accounts that must never be followed back.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gh-mutual-follow/internal/bulk"
+	"gh-mutual-follow/internal/logins"
+	"gh-mutual-follow/internal/xdg"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the content of the configuration file.
+type Config struct {
+	// Allow lists the accounts that are never unfollowed by a bulk action, sync or
+	// plan: maintainers of libraries we use, celebrities, anyone followed on purpose.
+	Allow []string `yaml:"allow,omitempty"`
+	// Deny lists the accounts that are never followed back, such as known spam accounts.
+	Deny []string `yaml:"deny,omitempty"`
+}
+
+// Allowed reports whether login is on the allowlist. Logins are case-insensitive.
+func (c Config) Allowed(login string) bool {
+	return contains(c.Allow, login)
+}
+
+// Denied reports whether login is on the denylist.
+func (c Config) Denied(login string) bool {
+	return contains(c.Deny, login)
+}
+
+// Protects reports whether the lists forbid running action on login: unfollowing an
+// allowlisted account, or following a denylisted one.
+func (c Config) Protects(action bulk.Action, login string) bool {
+	if action == bulk.Unfollow {
+		return c.Allowed(login)
+	}
+	return c.Denied(login)
+}
+
+// Filter splits logins into those action may run on and those the lists protect,
+// keeping their order.
+func (c Config) Filter(action bulk.Action, logins []string) (kept, protected []string) {
+	for _, login := range logins {
+		if c.Protects(action, login) {
+			protected = append(protected, login)
+		} else {
+			kept = append(kept, login)
+		}
+	}
+	return kept, protected
+}
+
+// TogglePin adds login to the allowlist, or removes it when it is already there, and
+// reports whether it is now allowlisted. A pinned login leaves the denylist.
+func (c *Config) TogglePin(login string) bool {
+	pinned := toggle(&c.Allow, login)
+	if pinned {
+		c.Deny = remove(c.Deny, login)
+	}
+	return pinned
+}
+
+// ToggleIgnore adds login to the denylist, or removes it when it is already there, and
+// reports whether it is now denylisted. An ignored login leaves the allowlist.
+func (c *Config) ToggleIgnore(login string) bool {
+	ignored := toggle(&c.Deny, login)
+	if ignored {
+		c.Allow = remove(c.Allow, login)
+	}
+	return ignored
+}
+
+// File is a YAML configuration file. It is safe for concurrent use.
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFile returns the configuration file at path. The file is created on the first Save.
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// DefaultPath returns the file location under the XDG config directory:
+// $XDG_CONFIG_HOME/gh-mutual-follow/config.yaml, or ~/.config/... when unset.
+func DefaultPath() (string, error) {
+	return xdg.ConfigPath("config.yaml")
+}
+
+// Path returns the location of the file.
+func (f *File) Path() string {
+	return f.path
+}
+
+// Load reads the configuration. A missing file is an empty configuration.
+func (f *File) Load() (Config, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config: %w", err)
+	}
+	var c Config
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config %s: %w", f.path, err)
+	}
+	return c, nil
+}
+
+// Save writes c with sorted lists, replacing the file atomically so that a crash
+// never leaves a truncated configuration behind.
+func (f *File) Save(c Config) error {
+	c.Allow, c.Deny = logins.Sorted(c.Allow), logins.Sorted(c.Deny)
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// contains reports whether logins has login, ignoring case as GitHub does.
+func contains(logins []string, login string) bool {
+	return slices.ContainsFunc(logins, func(l string) bool { return strings.EqualFold(l, login) })
+}
+
+// toggle adds login to *logins, or removes it when present, and reports whether it was added.
+func toggle(logins *[]string, login string) bool {
+	if contains(*logins, login) {
+		*logins = remove(*logins, login)
+		return false
+	}
+	*logins = append(slices.Clone(*logins), login)
+	return true
+}
+
+// remove returns logins without login.
+func remove(logins []string, login string) []string {
+	return slices.DeleteFunc(slices.Clone(logins), func(l string) bool { return strings.EqualFold(l, login) })
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gh-mutual-follow/internal/bulk"
+)
+
+func TestFile_LoadMissing(t *testing.T) {
+	f := NewFile(filepath.Join(t.TempDir(), "config.yaml"))
+	c, err := f.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Allow) != 0 || len(c.Deny) != 0 {
+		t.Errorf("expected an empty config, got %+v", c)
+	}
+}
+
+func TestFile_SaveAndLoad(t *testing.T) {
+	f := NewFile(filepath.Join(t.TempDir(), "gh-mutual-follow", "config.yaml"))
+	if err := f.Save(Config{Allow: []string{"torvalds", "gaearon"}, Deny: []string{"spammer"}}); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+
+	data, err := os.ReadFile(f.Path())
+	if err != nil {
+		t.Fatalf("failed to read the file: %v", err)
+	}
+	if want := "allow:\n    - gaearon\n    - torvalds\ndeny:\n    - spammer\n"; string(data) != want {
+		t.Errorf("unexpected file content:\n%s", data)
+	}
+
+	c, err := f.Load()
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if want := (Config{Allow: []string{"gaearon", "torvalds"}, Deny: []string{"spammer"}}); !reflect.DeepEqual(c, want) {
+		t.Errorf("expected %+v, got %+v", want, c)
+	}
+}
+
+func TestFile_LoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("allow: [unclosed\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFile(path).Load(); err == nil {
+		t.Error("expected an error for an invalid file")
+	}
+}
+
+func TestConfig_Filter(t *testing.T) {
+	c := Config{Allow: []string{"Torvalds"}, Deny: []string{"spammer"}}
+
+	kept, protected := c.Filter(bulk.Unfollow, []string{"alice", "torvalds", "spammer"})
+	if !reflect.DeepEqual(kept, []string{"alice", "spammer"}) || !reflect.DeepEqual(protected, []string{"torvalds"}) {
+		t.Errorf("unexpected unfollow split %v / %v", kept, protected)
+	}
+	kept, protected = c.Filter(bulk.Follow, []string{"alice", "torvalds", "SPAMMER"})
+	if !reflect.DeepEqual(kept, []string{"alice", "torvalds"}) || !reflect.DeepEqual(protected, []string{"SPAMMER"}) {
+		t.Errorf("unexpected follow split %v / %v", kept, protected)
+	}
+}
+
+func TestConfig_Toggle(t *testing.T) {
+	var c Config
+	if !c.TogglePin("alice") || !c.Allowed("alice") {
+		t.Fatalf("expected alice to be pinned, got %+v", c)
+	}
+	if !c.ToggleIgnore("alice") || !c.Denied("alice") || c.Allowed("alice") {
+		t.Errorf("ignoring a pinned user must unpin them, got %+v", c)
+	}
+	if c.ToggleIgnore("Alice") || c.Denied("alice") {
+		t.Errorf("expected alice to be unignored, got %+v", c)
+	}
+}
@@ -0,0 +1,242 @@
+package github
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long a CachingClient serves a list without revalidating it.
+const DefaultCacheTTL = 5 * time.Minute
+
+// revalidateTimeout bounds a background revalidation, rate limit waits included.
+const revalidateTimeout = 2 * time.Minute
+
+// Cached list kinds, used in the keys of CachingClient.lists.
+const (
+	listFollowing = "following"
+	listFollowers = "followers"
+)
+
+// CachingClient is a Client that keeps the following and followers lists it fetches
+// in memory. A list younger than the TTL is served without any request. An older one
+// is served at once while it is revalidated in the background (stale-while-revalidate),
+// and the revalidate observer is told when the new list differs.
+//
+// Follow and Unfollow update the cached following of the authenticated user instead
+// of dropping it, so reading the lists after a single action costs no request.
+// Revalidation goes through the wrapped client: the REST client sends If-None-Match
+// for every page, so an unchanged list costs neither quota nor a download.
+type CachingClient struct {
+	client       Client
+	ttl          time.Duration
+	onRevalidate func()
+	now          func() time.Time
+	ctx          context.Context // parent of the background revalidations
+
+	mu     sync.Mutex
+	viewer string                 // the authenticated user, once known
+	lists  map[string]*cachedList // "<kind>:<login>" -> list
+}
+
+// cachedList is a list held by a CachingClient.
+type cachedList struct {
+	users        []User
+	fetched      time.Time // zero once invalidated
+	version      int       // bumped by every local change, so older fetches are not stored over it
+	revalidating bool
+}
+
+// CacheOption configures a CachingClient.
+type CacheOption func(*CachingClient)
+
+// WithRevalidateObserver registers fn to be called after a background revalidation
+// changed a list. fn is called from the revalidating goroutine.
+func WithRevalidateObserver(fn func()) CacheOption {
+	return func(c *CachingClient) { c.onRevalidate = fn }
+}
+
+// WithBaseContext makes background revalidations stop when ctx is cancelled, for
+// instance when the program that reads the lists exits. The default is never cancelled.
+func WithBaseContext(ctx context.Context) CacheOption {
+	return func(c *CachingClient) { c.ctx = ctx }
+}
+
+// NewCachingClient wraps client so that its lists are reused for ttl.
+func NewCachingClient(client Client, ttl time.Duration, opts ...CacheOption) *CachingClient {
+	c := &CachingClient{client: client, ttl: ttl, now: time.Now, ctx: context.Background(), lists: make(map[string]*cachedList)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Invalidate makes the next read of every list fetch it again, for an explicit refresh.
+func (c *CachingClient) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, l := range c.lists {
+		l.fetched = time.Time{}
+	}
+}
+
+// GetUser returns the authenticated user, asking the wrapped client only once.
+func (c *CachingClient) GetUser(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	viewer := c.viewer
+	c.mu.Unlock()
+	if viewer != "" {
+		return viewer, nil
+	}
+	user, err := c.client.GetUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.viewer = user
+	c.mu.Unlock()
+	return user, nil
+}
+
+func (c *CachingClient) GetFollowing(ctx context.Context, user string) ([]string, error) {
+	users, err := c.GetFollowingUsers(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return Logins(users), nil
+}
+
+func (c *CachingClient) GetFollowers(ctx context.Context, user string) ([]string, error) {
+	users, err := c.GetFollowersUsers(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return Logins(users), nil
+}
+
+func (c *CachingClient) GetFollowingUsers(ctx context.Context, user string) ([]User, error) {
+	return c.list(ctx, listFollowing, user, c.client.GetFollowingUsers)
+}
+
+func (c *CachingClient) GetFollowersUsers(ctx context.Context, user string) ([]User, error) {
+	return c.list(ctx, listFollowers, user, c.client.GetFollowersUsers)
+}
+
+func (c *CachingClient) GetUserProfile(ctx context.Context, login string) (User, error) {
+	return c.client.GetUserProfile(ctx, login)
+}
+
+func (c *CachingClient) GetLastActivity(ctx context.Context, login string) (time.Time, error) {
+	return c.client.GetLastActivity(ctx, login)
+}
+
+// Follow follows user and adds them to the cached following.
+func (c *CachingClient) Follow(ctx context.Context, user string) error {
+	if err := c.client.Follow(ctx, user); err != nil {
+		return err
+	}
+	c.update(user, true)
+	return nil
+}
+
+// Unfollow unfollows user and removes them from the cached following.
+func (c *CachingClient) Unfollow(ctx context.Context, user string) error {
+	if err := c.client.Unfollow(ctx, user); err != nil {
+		return err
+	}
+	c.update(user, false)
+	return nil
+}
+
+// list returns the cached list of kind for user, fetching it when missing or
+// invalidated, and revalidating it in the background when older than the TTL.
+func (c *CachingClient) list(ctx context.Context, kind, user string, fetch func(context.Context, string) ([]User, error)) ([]User, error) {
+	key := kind + ":" + user
+	c.mu.Lock()
+	l, ok := c.lists[key]
+	if !ok {
+		l = &cachedList{}
+		c.lists[key] = l
+	}
+	if !l.fetched.IsZero() {
+		users := slices.Clone(l.users)
+		if c.now().Sub(l.fetched) >= c.ttl && !l.revalidating {
+			l.revalidating = true
+			go c.revalidate(l, user, l.version, fetch)
+		}
+		c.mu.Unlock()
+		return users, nil
+	}
+	version := l.version
+	c.mu.Unlock()
+
+	users, err := fetch(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.store(l, version, users)
+	c.mu.Unlock()
+	return users, nil
+}
+
+// revalidate fetches a stale list again and stores it, telling the observer when it changed.
+func (c *CachingClient) revalidate(l *cachedList, user string, version int, fetch func(context.Context, string) ([]User, error)) {
+	ctx, cancel := context.WithTimeout(c.ctx, revalidateTimeout)
+	defer cancel()
+	users, err := fetch(ctx, user)
+	c.mu.Lock()
+	l.revalidating = false
+	changed := err == nil && !slices.Equal(Logins(users), Logins(l.users))
+	if err == nil {
+		changed = c.store(l, version, users) && changed
+	}
+	c.mu.Unlock()
+	if changed && c.onRevalidate != nil {
+		c.onRevalidate()
+	}
+}
+
+// store saves users fetched when the list was at version, unless it changed locally
+// since: the fetch may predate the change. Reports whether users were stored.
+// c.mu must be held.
+func (c *CachingClient) store(l *cachedList, version int, users []User) bool {
+	if l.version != version {
+		return false
+	}
+	l.users = slices.Clone(users)
+	l.fetched = c.now()
+	return true
+}
+
+// update applies a successful follow or unfollow of login to the cached following
+// of the authenticated user. A followed user gets their profile from the cached
+// followers when they are there, and only their login otherwise.
+func (c *CachingClient) update(login string, follow bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.viewer == "" {
+		return
+	}
+	l, ok := c.lists[listFollowing+":"+c.viewer]
+	if !ok {
+		return
+	}
+	i := slices.IndexFunc(l.users, func(u User) bool { return u.Login == login })
+	switch {
+	case follow && i < 0:
+		u := User{Login: login}
+		if followers, ok := c.lists[listFollowers+":"+c.viewer]; ok {
+			if j := slices.IndexFunc(followers.users, func(f User) bool { return f.Login == login }); j >= 0 {
+				u = followers.users[j]
+			}
+		}
+		l.users = append(l.users, u)
+	case !follow && i >= 0:
+		l.users = slices.Delete(slices.Clone(l.users), i, i+1)
+	default:
+		return
+	}
+	l.version++
+}
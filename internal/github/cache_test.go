@@ -0,0 +1,183 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingClient is a Client over mutable lists that counts the list fetches.
+type countingClient struct {
+	stubClient
+	mu      sync.Mutex
+	fetches int
+	failing error // returned by Follow and Unfollow
+}
+
+func (c *countingClient) GetFollowingUsers(ctx context.Context, user string) ([]User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetches++
+	return usersOf(c.following), nil
+}
+
+func (c *countingClient) GetFollowersUsers(ctx context.Context, user string) ([]User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetches++
+	return []User{{Login: "carol", Name: "Carol"}}, nil
+}
+
+func (c *countingClient) Follow(ctx context.Context, user string) error   { return c.failing }
+func (c *countingClient) Unfollow(ctx context.Context, user string) error { return c.failing }
+
+func (c *countingClient) setFollowing(logins ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.following = logins
+}
+
+func (c *countingClient) fetchCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fetches
+}
+
+func TestCachingClient_ServesWithinTTL(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingClient{stubClient: stubClient{t: t, following: []string{"alice"}}}
+	client := NewCachingClient(inner, time.Hour)
+
+	for range 3 {
+		following, err := client.GetFollowing(ctx, "me")
+		if err != nil || !reflect.DeepEqual(following, []string{"alice"}) {
+			t.Fatalf("unexpected following %v (err=%v)", following, err)
+		}
+	}
+	if n := inner.fetchCount(); n != 1 {
+		t.Errorf("expected 1 fetch within the TTL, got %d", n)
+	}
+
+	inner.setFollowing("alice", "bob")
+	client.Invalidate()
+	following, _ := client.GetFollowing(ctx, "me")
+	if !reflect.DeepEqual(following, []string{"alice", "bob"}) || inner.fetchCount() != 2 {
+		t.Errorf("expected an invalidated list to be fetched again, got %v after %d fetches", following, inner.fetchCount())
+	}
+}
+
+func TestCachingClient_StaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingClient{stubClient: stubClient{t: t, following: []string{"alice"}}}
+	revalidated := make(chan struct{}, 1)
+	client := NewCachingClient(inner, time.Minute, WithRevalidateObserver(func() { revalidated <- struct{}{} }))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+
+	if _, err := client.GetFollowing(ctx, "me"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inner.setFollowing("alice", "bob")
+	now = now.Add(2 * time.Minute)
+
+	// The stale list is served at once, and refreshed in the background.
+	following, _ := client.GetFollowing(ctx, "me")
+	if !reflect.DeepEqual(following, []string{"alice"}) {
+		t.Errorf("expected the stale list, got %v", following)
+	}
+	select {
+	case <-revalidated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the list was not revalidated")
+	}
+	following, _ = client.GetFollowing(ctx, "me")
+	if !reflect.DeepEqual(following, []string{"alice", "bob"}) {
+		t.Errorf("expected the revalidated list, got %v", following)
+	}
+	if n := inner.fetchCount(); n != 2 {
+		t.Errorf("expected 2 fetches, got %d", n)
+	}
+}
+
+func TestCachingClient_MutationsUpdateTheCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingClient{stubClient: stubClient{t: t, following: []string{"alice", "bob"}}}
+	client := NewCachingClient(inner, time.Hour)
+
+	user, _ := client.GetUser(ctx)
+	if _, err := client.GetFollowingUsers(ctx, user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.GetFollowersUsers(ctx, user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := client.Unfollow(ctx, "alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Follow(ctx, "carol"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	following, _ := client.GetFollowingUsers(ctx, user)
+	if want := []User{{Login: "bob"}, {Login: "carol", Name: "Carol"}}; !reflect.DeepEqual(following, want) {
+		t.Errorf("expected %v, got %v", want, following)
+	}
+
+	inner.failing = errors.New("forbidden")
+	if err := client.Unfollow(ctx, "bob"); err == nil {
+		t.Fatal("expected the error of the wrapped client")
+	}
+	if following, _ := client.GetFollowing(ctx, user); !reflect.DeepEqual(following, []string{"bob", "carol"}) {
+		t.Errorf("a failed unfollow must not change the cache, got %v", following)
+	}
+	if n := inner.fetchCount(); n != 2 {
+		t.Errorf("expected no fetch after the mutations, got %d fetches", n)
+	}
+}
+
+// blockingClient is a Client whose list fetches after the first one block until
+// their context ends, reporting why.
+type blockingClient struct {
+	stubClient
+	calls   int
+	stopped chan error
+}
+
+func (c *blockingClient) GetFollowingUsers(ctx context.Context, user string) ([]User, error) {
+	c.calls++
+	if c.calls == 1 {
+		return []User{{Login: "alice"}}, nil
+	}
+	<-ctx.Done()
+	c.stopped <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func TestCachingClient_RevalidationStopsWithBaseContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	inner := &blockingClient{stubClient: stubClient{t: t}, stopped: make(chan error, 1)}
+	client := NewCachingClient(inner, time.Minute, WithBaseContext(ctx))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+
+	if _, err := client.GetFollowingUsers(context.Background(), "me"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := client.GetFollowingUsers(context.Background(), "me"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cancel()
+	select {
+	case err := <-inner.stopped:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the revalidation to be cancelled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the revalidation did not stop with the base context")
+	}
+}
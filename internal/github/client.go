@@ -2,39 +2,50 @@ package github
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Client defines the interface for interacting with the GitHub API.
+// Every call is bounded by ctx; cancelling it aborts the outstanding request or command.
 type Client interface {
-	GetUser() (string, error)
-	GetFollowing(user string) ([]string, error)
-	GetFollowers(user string) ([]string, error)
-	Unfollow(user string) error
-	Follow(user string) error
+	GetUser(ctx context.Context) (string, error)
+	GetFollowing(ctx context.Context, user string) ([]string, error)
+	GetFollowers(ctx context.Context, user string) ([]string, error)
+	GetFollowingUsers(ctx context.Context, user string) ([]User, error)
+	GetFollowersUsers(ctx context.Context, user string) ([]User, error)
+	GetUserProfile(ctx context.Context, login string) (User, error)
+	// GetLastActivity returns the time of the user's latest public event, or the zero time if there is none.
+	GetLastActivity(ctx context.Context, login string) (time.Time, error)
+	Unfollow(ctx context.Context, user string) error
+	Follow(ctx context.Context, user string) error
 }
 
 // commandRunner defines an interface for running external commands.
 // This makes the client testable by allowing mock runners.
 type commandRunner interface {
-	run(name string, args ...string) ([]byte, error)
+	run(ctx context.Context, name string, args ...string) ([]byte, error)
 }
 
 // execCommandRunner is the concrete implementation of commandRunner that uses os/exec.
 type execCommandRunner struct{}
 
-func (r *execCommandRunner) run(name string, args ...string) ([]byte, error) {
-	cmd := exec.Command(name, args...)
+func (r *execCommandRunner) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("command '%s %s' aborted: %w", name, strings.Join(args, " "), ctxErr)
+		}
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			return nil, fmt.Errorf("command '%s %s' failed with exit code %d: %s (stderr: %s)",
 				name, strings.Join(args, " "), exitErr.ExitCode(), err, stderr.String())
@@ -48,27 +59,40 @@ func (r *execCommandRunner) run(name string, args ...string) ([]byte, error) {
 // ghClient is the concrete implementation of the Client interface.
 type ghClient struct {
 	runner commandRunner
+	opts   options
 }
 
 // NewClient creates a new instance of ghClient with the default command runner.
-func NewClient() Client {
-	return &ghClient{runner: &execCommandRunner{}}
+func NewClient(opts ...Option) Client {
+	return &ghClient{runner: &execCommandRunner{}, opts: newOptions(opts)}
 }
 
 // NewClientWithRunner is a constructor for testing, allowing a mock runner to be injected.
-func NewClientWithRunner(runner commandRunner) Client {
-	return &ghClient{runner: runner}
+func NewClientWithRunner(runner commandRunner, opts ...Option) Client {
+	return &ghClient{runner: runner, opts: newOptions(opts)}
 }
 
-
-// GitHubUser represents a simplified GitHub user for JSON unmarshalling.
-type GitHubUser struct {
-	Login string `json:"login"`
+// run executes a command bounded by ctx and the per-call timeout.
+// gh does not expose the rate limit headers, so a command failing with a
+// rate limit message is retried with exponential backoff.
+func (c *ghClient) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	ctx, cancel := c.opts.callContext(ctx)
+	defer cancel()
+
+	for attempt := 0; ; attempt++ {
+		output, err := c.runner.run(ctx, name, args...)
+		if err == nil || !isRateLimitMessage(err.Error()) || attempt >= c.opts.maxRetries {
+			return output, err
+		}
+		if pauseErr := c.opts.pause(ctx, RateLimit{}, c.opts.backoff<<attempt); pauseErr != nil {
+			return nil, fmt.Errorf("%w (while waiting for rate limit: %w)", err, pauseErr)
+		}
+	}
 }
 
 // GetUser returns the GitHub username of the authenticated user.
-func (c *ghClient) GetUser() (string, error) {
-	output, err := c.runner.run("gh", "auth", "status")
+func (c *ghClient) GetUser(ctx context.Context) (string, error) {
+	output, err := c.run(ctx, "gh", "auth", "status")
 	if err != nil {
 		return "", fmt.Errorf("failed to run 'gh auth status': %w", err)
 	}
@@ -83,46 +107,82 @@ func (c *ghClient) GetUser() (string, error) {
 }
 
 // GetFollowing returns a list of users that the given user is following.
-func (c *ghClient) GetFollowing(user string) ([]string, error) {
-	output, err := c.runner.run("gh", "api", "--paginate", "users/"+user+"/following")
+func (c *ghClient) GetFollowing(ctx context.Context, user string) ([]string, error) {
+	users, err := c.GetFollowingUsers(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return Logins(users), nil
+}
+
+// GetFollowers returns a list of users that are following the given user.
+func (c *ghClient) GetFollowers(ctx context.Context, user string) ([]string, error) {
+	users, err := c.GetFollowersUsers(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return Logins(users), nil
+}
+
+// GetFollowingUsers returns the profiles of users that the given user is following.
+func (c *ghClient) GetFollowingUsers(ctx context.Context, user string) ([]User, error) {
+	output, err := c.run(ctx, "gh", "api", "--paginate", "users/"+user+"/following")
 	if err != nil {
 		return nil, fmt.Errorf("failed to run 'gh api users/%s/following': %w", user, err)
 	}
 
-	var users []GitHubUser
+	var users []User
 	if err := json.Unmarshal(output, &users); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON from 'gh api users/%s/following': %w", user, err)
 	}
-
-	var following []string
-	for _, u := range users {
-		following = append(following, u.Login)
-	}
-	return following, nil
+	return users, nil
 }
 
-// GetFollowers returns a list of users that are following the given user.
-func (c *ghClient) GetFollowers(user string) ([]string, error) {
-	output, err := c.runner.run("gh", "api", "--paginate", "users/"+user+"/followers")
+// GetFollowersUsers returns the profiles of users that are following the given user.
+func (c *ghClient) GetFollowersUsers(ctx context.Context, user string) ([]User, error) {
+	output, err := c.run(ctx, "gh", "api", "--paginate", "users/"+user+"/followers")
 	if err != nil {
 		return nil, fmt.Errorf("failed to run 'gh api users/%s/followers': %w", user, err)
 	}
 
-	var users []GitHubUser
+	var users []User
 	if err := json.Unmarshal(output, &users); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON from 'gh api users/%s/followers': %w", user, err)
 	}
+	return users, nil
+}
+
+// GetUserProfile returns the full profile of the given user.
+func (c *ghClient) GetUserProfile(ctx context.Context, login string) (User, error) {
+	output, err := c.run(ctx, "gh", "api", "users/"+login)
+	if err != nil {
+		return User{}, fmt.Errorf("failed to run 'gh api users/%s': %w", login, err)
+	}
+
+	var u User
+	if err := json.Unmarshal(output, &u); err != nil {
+		return User{}, fmt.Errorf("failed to parse JSON from 'gh api users/%s': %w", login, err)
+	}
+	return u, nil
+}
 
-	var followers []string
-	for _, u := range users {
-		followers = append(followers, u.Login)
+// GetLastActivity returns the time of the latest public event of the given user.
+func (c *ghClient) GetLastActivity(ctx context.Context, login string) (time.Time, error) {
+	output, err := c.run(ctx, "gh", "api", "users/"+login+"/events/public?per_page=1")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to run 'gh api users/%s/events/public': %w", login, err)
 	}
-	return followers, nil
+
+	var events []publicEvent
+	if err := json.Unmarshal(output, &events); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse JSON from 'gh api users/%s/events/public': %w", login, err)
+	}
+	return latestEvent(events), nil
 }
 
 // Unfollow unfollows a given user.
-func (c *ghClient) Unfollow(user string) error {
-	_, err := c.runner.run("gh", "api", "--method", "DELETE", "user/following/"+user)
+func (c *ghClient) Unfollow(ctx context.Context, user string) error {
+	_, err := c.run(ctx, "gh", "api", "--method", "DELETE", "user/following/"+user)
 	if err != nil {
 		return fmt.Errorf("failed to unfollow %s: %w", user, err)
 	}
@@ -130,17 +190,17 @@ func (c *ghClient) Unfollow(user string) error {
 }
 
 // Follow follows a given user.
-func (c *ghClient) Follow(user string) error {
-	_, err := c.runner.run("gh", "api", "--method", "PUT", "user/following/"+user)
+func (c *ghClient) Follow(ctx context.Context, user string) error {
+	_, err := c.run(ctx, "gh", "api", "--method", "PUT", "user/following/"+user)
 	if err != nil {
 		return fmt.Errorf("failed to follow %s: %w", user, err)
 	}
 	return nil
 }
 
-// GetMutualFollowsData calculates the 'only following' and 'only followers' lists.
+// GetMutualFollowsData calculates the 'only following', 'only followers' and 'mutual' lists.
 // This is a pure function and does not need to be a method on the client.
-func GetMutualFollowsData(authenticatedUser string, following, followers []string) (onlyFollowing []string, onlyFollowers []string) {
+func GetMutualFollowsData(authenticatedUser string, following, followers []string) (onlyFollowing, onlyFollowers, mutual []string) {
 	followingMap := make(map[string]bool)
 	for _, u := range following {
 		followingMap[u] = true
@@ -153,9 +213,12 @@ func GetMutualFollowsData(authenticatedUser string, following, followers []strin
 
 	// Use temporary maps to collect unique results
 	uniqueOnlyFollowing := make(map[string]bool)
+	uniqueMutual := make(map[string]bool)
 	for _, u := range following {
 		if _, exists := followersMap[u]; !exists {
 			uniqueOnlyFollowing[u] = true
+		} else {
+			uniqueMutual[u] = true
 		}
 	}
 
@@ -173,6 +236,9 @@ func GetMutualFollowsData(authenticatedUser string, following, followers []strin
 	for u := range uniqueOnlyFollowers {
 		onlyFollowers = append(onlyFollowers, u)
 	}
+	for u := range uniqueMutual {
+		mutual = append(mutual, u)
+	}
 
-	return onlyFollowing, onlyFollowers
-}
\ No newline at end of file
+	return onlyFollowing, onlyFollowers, mutual
+}
@@ -1,11 +1,13 @@
 package github
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 // mockCommandRunner is a mock implementation of the commandRunner interface for testing.
@@ -13,7 +15,7 @@ type mockCommandRunner struct {
 	runFunc func(name string, args ...string) ([]byte, error)
 }
 
-func (m *mockCommandRunner) run(name string, args ...string) ([]byte, error) {
+func (m *mockCommandRunner) run(ctx context.Context, name string, args ...string) ([]byte, error) {
 	if m.runFunc != nil {
 		return m.runFunc(name, args...)
 	}
@@ -60,7 +62,7 @@ func TestGetUser(t *testing.T) {
 			}
 			client := NewClientWithRunner(runner)
 
-			user, err := client.GetUser()
+			user, err := client.GetUser(context.Background())
 
 			if tt.expectedErr != "" {
 				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
@@ -117,7 +119,7 @@ func TestGetFollowing(t *testing.T) {
 			}
 			client := NewClientWithRunner(runner)
 
-			following, err := client.GetFollowing(tt.user)
+			following, err := client.GetFollowing(context.Background(), tt.user)
 
 			if tt.expectedErr != "" {
 				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
@@ -136,7 +138,7 @@ func TestGetFollowing(t *testing.T) {
 }
 
 func TestGetFollowers(t *testing.T) {
-    // Similar structure to TestGetFollowing
+	// Similar structure to TestGetFollowing
 	tests := []struct {
 		name        string
 		user        string
@@ -168,7 +170,7 @@ func TestGetFollowers(t *testing.T) {
 			}
 			client := NewClientWithRunner(runner)
 
-			followers, err := client.GetFollowers(tt.user)
+			followers, err := client.GetFollowers(context.Background(), tt.user)
 
 			if tt.expectedErr != "" {
 				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
@@ -214,7 +216,7 @@ func TestUnfollow(t *testing.T) {
 			}
 			client := NewClientWithRunner(runner)
 
-			err := client.Unfollow(tt.user)
+			err := client.Unfollow(context.Background(), tt.user)
 
 			if tt.expectedErr != "" {
 				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
@@ -255,7 +257,7 @@ func TestFollow(t *testing.T) {
 			}
 			client := NewClientWithRunner(runner)
 
-			err := client.Follow(tt.user)
+			err := client.Follow(context.Background(), tt.user)
 
 			if tt.expectedErr != "" {
 				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
@@ -290,6 +292,7 @@ func TestGetMutualFollowsData(t *testing.T) {
 		followers             []string
 		expectedOnlyFollowing []string
 		expectedOnlyFollowers []string
+		expectedMutual        []string
 	}{
 		{
 			name:                  "Standard case",
@@ -297,6 +300,7 @@ func TestGetMutualFollowsData(t *testing.T) {
 			followers:             []string{"b", "c", "d"},
 			expectedOnlyFollowing: []string{"a"},
 			expectedOnlyFollowers: []string{"d"},
+			expectedMutual:        []string{"b", "c"},
 		},
 		{
 			name:                  "Empty following",
@@ -304,6 +308,7 @@ func TestGetMutualFollowsData(t *testing.T) {
 			followers:             []string{"a", "b"},
 			expectedOnlyFollowing: []string{},
 			expectedOnlyFollowers: []string{"a", "b"},
+			expectedMutual:        []string{},
 		},
 		{
 			name:                  "Empty followers",
@@ -311,6 +316,7 @@ func TestGetMutualFollowsData(t *testing.T) {
 			followers:             []string{},
 			expectedOnlyFollowing: []string{"a", "b"},
 			expectedOnlyFollowers: []string{},
+			expectedMutual:        []string{},
 		},
 		{
 			name:                  "All mutual",
@@ -318,12 +324,13 @@ func TestGetMutualFollowsData(t *testing.T) {
 			followers:             []string{"a", "b"},
 			expectedOnlyFollowing: []string{},
 			expectedOnlyFollowers: []string{},
+			expectedMutual:        []string{"a", "b"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			onlyFollowing, onlyFollowers := GetMutualFollowsData("user", tt.following, tt.followers)
+			onlyFollowing, onlyFollowers, mutual := GetMutualFollowsData("user", tt.following, tt.followers)
 
 			if !compareStringSlices(onlyFollowing, tt.expectedOnlyFollowing) {
 				t.Errorf("Expected onlyFollowing %v, got %v", tt.expectedOnlyFollowing, onlyFollowing)
@@ -331,6 +338,76 @@ func TestGetMutualFollowsData(t *testing.T) {
 			if !compareStringSlices(onlyFollowers, tt.expectedOnlyFollowers) {
 				t.Errorf("Expected onlyFollowers %v, got %v", tt.expectedOnlyFollowers, onlyFollowers)
 			}
+			if !compareStringSlices(mutual, tt.expectedMutual) {
+				t.Errorf("Expected mutual %v, got %v", tt.expectedMutual, mutual)
+			}
 		})
 	}
 }
+
+func TestGetUserProfile(t *testing.T) {
+	runner := &mockCommandRunner{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if strings.Join(args, " ") != "api users/alice" {
+				t.Errorf("unexpected args %v", args)
+			}
+			return []byte(`{"login": "alice", "name": "Alice", "followers": 3, "type": "User"}`), nil
+		},
+	}
+	client := NewClientWithRunner(runner)
+
+	u, err := client.GetUserProfile(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u.Login != "alice" || u.Name != "Alice" || u.Followers != 3 || u.Type != "User" {
+		t.Errorf("unexpected profile %+v", u)
+	}
+}
+
+func TestGetLastActivity(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected time.Time
+	}{
+		{"Recent event", `[{"created_at": "2024-05-01T12:00:00Z"}]`, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"No public events", `[]`, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &mockCommandRunner{
+				runFunc: func(name string, args ...string) ([]byte, error) {
+					if strings.Join(args, " ") != "api users/alice/events/public?per_page=1" {
+						t.Errorf("unexpected args %v", args)
+					}
+					return []byte(tt.output), nil
+				},
+			}
+			last, err := NewClientWithRunner(runner).GetLastActivity(context.Background(), "alice")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !last.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, last)
+			}
+		})
+	}
+}
+
+func TestClientPassesContextToRunner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runner := &mockCommandRunner{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			cancel()
+			return nil, context.Canceled
+		},
+	}
+	client := NewClientWithRunner(runner, WithTimeout(time.Minute))
+
+	err := client.Unfollow(ctx, "testuser")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Mutation is a follow or unfollow recorded by a DryRunClient.
+type Mutation struct {
+	Login  string
+	Follow bool // true for a follow, false for an unfollow
+}
+
+func (m Mutation) String() string {
+	if m.Follow {
+		return "follow " + m.Login
+	}
+	return "unfollow " + m.Login
+}
+
+// DryRunClient is a Client that reads through to another client but only records
+// Follow and Unfollow in memory. Later reads of the authenticated user's following
+// reflect the recorded mutations, so a dry run behaves like the real thing.
+type DryRunClient struct {
+	client Client
+
+	mu        sync.Mutex
+	viewer    string          // the authenticated user, once known
+	following map[string]bool // login -> followed after the recorded mutations
+	mutations []Mutation
+}
+
+// NewDryRunClient wraps client so that nothing is changed on GitHub.
+func NewDryRunClient(client Client) *DryRunClient {
+	return &DryRunClient{client: client, following: make(map[string]bool)}
+}
+
+// Mutations returns the recorded follows and unfollows, in order.
+func (c *DryRunClient) Mutations() []Mutation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Mutation(nil), c.mutations...)
+}
+
+func (c *DryRunClient) GetUser(ctx context.Context) (string, error) {
+	user, err := c.client.GetUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.viewer = user
+	c.mu.Unlock()
+	return user, nil
+}
+
+// viewerLogin returns the authenticated user, asking GitHub the first time so that
+// callers need not call GetUser before reading their own following.
+func (c *DryRunClient) viewerLogin(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	viewer := c.viewer
+	c.mu.Unlock()
+	if viewer != "" {
+		return viewer, nil
+	}
+	return c.GetUser(ctx)
+}
+
+func (c *DryRunClient) GetFollowing(ctx context.Context, user string) ([]string, error) {
+	users, err := c.GetFollowingUsers(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return Logins(users), nil
+}
+
+func (c *DryRunClient) GetFollowers(ctx context.Context, user string) ([]string, error) {
+	return c.client.GetFollowers(ctx, user)
+}
+
+// GetFollowingUsers returns the users followed by user. For the authenticated user,
+// unfollowed users are removed and followed users are appended with their login only.
+func (c *DryRunClient) GetFollowingUsers(ctx context.Context, user string) ([]User, error) {
+	users, err := c.client.GetFollowingUsers(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	pending := len(c.mutations) > 0
+	c.mu.Unlock()
+	if !pending {
+		return users, nil
+	}
+	viewer, err := c.viewerLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user != viewer {
+		return users, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make([]User, 0, len(users))
+	seen := make(map[string]bool, len(users))
+	for _, u := range users {
+		seen[u.Login] = true
+		if followed, ok := c.following[u.Login]; !ok || followed {
+			result = append(result, u)
+		}
+	}
+	for _, m := range c.mutations {
+		if !seen[m.Login] && c.following[m.Login] {
+			seen[m.Login] = true
+			result = append(result, User{Login: m.Login})
+		}
+	}
+	return result, nil
+}
+
+func (c *DryRunClient) GetFollowersUsers(ctx context.Context, user string) ([]User, error) {
+	return c.client.GetFollowersUsers(ctx, user)
+}
+
+func (c *DryRunClient) GetUserProfile(ctx context.Context, login string) (User, error) {
+	return c.client.GetUserProfile(ctx, login)
+}
+
+func (c *DryRunClient) GetLastActivity(ctx context.Context, login string) (time.Time, error) {
+	return c.client.GetLastActivity(ctx, login)
+}
+
+// Follow records a follow of user without calling GitHub.
+func (c *DryRunClient) Follow(ctx context.Context, user string) error {
+	return c.record(ctx, user, true)
+}
+
+// Unfollow records an unfollow of user without calling GitHub.
+func (c *DryRunClient) Unfollow(ctx context.Context, user string) error {
+	return c.record(ctx, user, false)
+}
+
+func (c *DryRunClient) record(ctx context.Context, user string, follow bool) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("dry run interrupted: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.following[user] = follow
+	c.mutations = append(c.mutations, Mutation{Login: user, Follow: follow})
+	return nil
+}
@@ -0,0 +1,123 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubClient is a read-only Client over fixed lists. Follow and Unfollow fail the test.
+type stubClient struct {
+	t         *testing.T
+	following []string
+	followers []string
+}
+
+func (s *stubClient) GetUser(ctx context.Context) (string, error) { return "me", nil }
+func (s *stubClient) GetFollowing(ctx context.Context, user string) ([]string, error) {
+	return s.following, nil
+}
+func (s *stubClient) GetFollowers(ctx context.Context, user string) ([]string, error) {
+	return s.followers, nil
+}
+func (s *stubClient) GetFollowingUsers(ctx context.Context, user string) ([]User, error) {
+	return usersOf(s.following), nil
+}
+func (s *stubClient) GetFollowersUsers(ctx context.Context, user string) ([]User, error) {
+	return usersOf(s.followers), nil
+}
+func (s *stubClient) GetUserProfile(ctx context.Context, login string) (User, error) {
+	return User{Login: login, Name: "Profile"}, nil
+}
+func (s *stubClient) GetLastActivity(ctx context.Context, login string) (time.Time, error) {
+	return time.Time{}, nil
+}
+func (s *stubClient) Follow(ctx context.Context, user string) error {
+	s.t.Errorf("unexpected Follow(%s) on the real client", user)
+	return nil
+}
+func (s *stubClient) Unfollow(ctx context.Context, user string) error {
+	s.t.Errorf("unexpected Unfollow(%s) on the real client", user)
+	return nil
+}
+
+func usersOf(logins []string) []User {
+	users := make([]User, len(logins))
+	for i, l := range logins {
+		users[i] = User{Login: l}
+	}
+	return users
+}
+
+func TestDryRunClient(t *testing.T) {
+	ctx := context.Background()
+	client := NewDryRunClient(&stubClient{t: t, following: []string{"alice", "bob"}, followers: []string{"bob", "carol"}})
+
+	user, err := client.GetUser(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Unfollow(ctx, "alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Follow(ctx, "carol"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	following, err := client.GetFollowing(ctx, user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"bob", "carol"}; !compareStringSlices(following, expected) {
+		t.Errorf("expected following %v, got %v", expected, following)
+	}
+	followers, _ := client.GetFollowers(ctx, user)
+	if expected := []string{"bob", "carol"}; !compareStringSlices(followers, expected) {
+		t.Errorf("expected followers to be unchanged, got %v", followers)
+	}
+	other, _ := client.GetFollowing(ctx, "someone")
+	if expected := []string{"alice", "bob"}; !compareStringSlices(other, expected) {
+		t.Errorf("expected other users' following to be unchanged, got %v", other)
+	}
+
+	// Following again restores the original entry.
+	client.Follow(ctx, "alice")
+	following, _ = client.GetFollowing(ctx, user)
+	if expected := []string{"alice", "bob", "carol"}; !compareStringSlices(following, expected) {
+		t.Errorf("expected following %v, got %v", expected, following)
+	}
+
+	expected := []Mutation{{"alice", false}, {"carol", true}, {"alice", true}}
+	if got := client.Mutations(); len(got) != len(expected) || got[0] != expected[0] || got[1] != expected[1] || got[2] != expected[2] {
+		t.Errorf("expected mutations %v, got %v", expected, got)
+	}
+}
+
+func TestDryRunClient_ViewerWithoutGetUser(t *testing.T) {
+	ctx := context.Background()
+	client := NewDryRunClient(&stubClient{t: t, following: []string{"alice", "bob"}})
+
+	client.Unfollow(ctx, "alice")
+	client.Follow(ctx, "carol")
+	following, err := client.GetFollowing(ctx, "me")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"bob", "carol"}; !compareStringSlices(following, expected) {
+		t.Errorf("expected following %v, got %v", expected, following)
+	}
+}
+
+func TestDryRunClient_Cancelled(t *testing.T) {
+	client := NewDryRunClient(&stubClient{t: t})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := client.Follow(ctx, "alice"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if len(client.Mutations()) != 0 {
+		t.Error("expected nothing to be recorded")
+	}
+}
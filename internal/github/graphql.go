@@ -0,0 +1,319 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// relationshipsQuery fetches one page of following and followers in a single round trip.
+// Either connection can be skipped once it has been fully paginated.
+const relationshipsQuery = `query($login: String!, $followingAfter: String, $followersAfter: String, $withFollowing: Boolean!, $withFollowers: Boolean!) {
+  user(login: $login) {
+    following(first: 100, after: $followingAfter) @include(if: $withFollowing) {
+      pageInfo { hasNextPage endCursor }
+      nodes { ...userFields }
+    }
+    followers(first: 100, after: $followersAfter) @include(if: $withFollowers) {
+      pageInfo { hasNextPage endCursor }
+      nodes { ...userFields }
+    }
+  }
+}
+
+fragment userFields on User {
+  __typename login name avatarUrl bio company location createdAt isSiteAdmin
+  followers { totalCount }
+  following { totalCount }
+  repositories(privacy: PUBLIC) { totalCount }
+}`
+
+const viewerQuery = `query { viewer { login } }`
+
+// graphqlCount is a connection of which only the total count is requested.
+type graphqlCount struct {
+	TotalCount int `json:"totalCount"`
+}
+
+// graphqlUser is a user node as returned by the GraphQL API.
+type graphqlUser struct {
+	Typename     string       `json:"__typename"`
+	Login        string       `json:"login"`
+	Name         string       `json:"name"`
+	AvatarURL    string       `json:"avatarUrl"`
+	Bio          string       `json:"bio"`
+	Company      string       `json:"company"`
+	Location     string       `json:"location"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	IsSiteAdmin  bool         `json:"isSiteAdmin"`
+	Followers    graphqlCount `json:"followers"`
+	Following    graphqlCount `json:"following"`
+	Repositories graphqlCount `json:"repositories"`
+}
+
+// toUser converts a GraphQL user node into a User.
+func (u graphqlUser) toUser() User {
+	return User{
+		Login:       u.Login,
+		Name:        u.Name,
+		AvatarURL:   u.AvatarURL,
+		Bio:         u.Bio,
+		Company:     u.Company,
+		Location:    u.Location,
+		Followers:   u.Followers.TotalCount,
+		Following:   u.Following.TotalCount,
+		PublicRepos: u.Repositories.TotalCount,
+		CreatedAt:   u.CreatedAt,
+		Type:        u.Typename,
+		SiteAdmin:   u.IsSiteAdmin,
+	}
+}
+
+// graphqlConnection is a paginated list of users.
+type graphqlConnection struct {
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+	Nodes []graphqlUser `json:"nodes"`
+}
+
+// graphqlError is a single entry of the "errors" array in a GraphQL response.
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+// graphqlClient is an implementation of the Client interface that fetches
+// following and followers through the GitHub GraphQL API. Both lists are
+// paginated together, so loading them costs roughly half the requests of REST.
+// Follow, Unfollow and GetUserProfile are delegated to the REST API.
+//
+// Because the Client interface asks for each list separately, the list fetched
+// alongside the requested one is kept for the next call: a GetFollowingUsers
+// followed within prefetchTTL by a GetFollowersUsers for the same login (or the
+// other way round) costs one set of requests. The kept list serves a single call;
+// any other call fetches both lists again.
+type graphqlClient struct {
+	*restClient
+
+	now func() time.Time
+
+	mu sync.Mutex
+	// prefetched holds lists fetched alongside another call, keyed by "following:<login>"
+	// or "followers:<login>". Each entry is consumed by the next matching call, unless
+	// it is older than prefetchTTL.
+	prefetched map[string]prefetchedList
+}
+
+// prefetchTTL is how long a list fetched alongside another one may serve the next
+// call for it. It covers the two calls of one load, not a later refresh.
+const prefetchTTL = 10 * time.Second
+
+// prefetchedList is a list fetched alongside another call.
+type prefetchedList struct {
+	users   []graphqlUser
+	fetched time.Time
+}
+
+// NewGraphQLClient creates a Client that uses the GitHub GraphQL API with the given token.
+func NewGraphQLClient(token string, opts ...Option) Client {
+	c, _ := NewGraphQLClientWithHTTP(http.DefaultClient, DefaultBaseURL, token, opts...)
+	return c
+}
+
+// NewGraphQLClientWithHTTP creates a GraphQL Client with a custom HTTP client and base URL.
+// The GraphQL endpoint is resolved as "graphql" relative to baseURL.
+func NewGraphQLClientWithHTTP(httpClient *http.Client, baseURL, token string, opts ...Option) (Client, error) {
+	u, err := parseBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &graphqlClient{
+		restClient: newRESTClient(httpClient, u, token, opts),
+		now:        time.Now,
+		prefetched: make(map[string]prefetchedList),
+	}, nil
+}
+
+// query executes a GraphQL query and decodes the "data" field into out.
+func (c *graphqlClient) query(ctx context.Context, query string, variables map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	u, err := c.baseURL.Parse("graphql")
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(ctx, http.MethodPost, u.String(), body, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphqlError  `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return fmt.Errorf("failed to parse GraphQL response: %w", err)
+	}
+	if len(payload.Errors) > 0 {
+		messages := make([]string, len(payload.Errors))
+		for i, e := range payload.Errors {
+			messages[i] = e.Message
+		}
+		return fmt.Errorf("GraphQL error: %s", strings.Join(messages, "; "))
+	}
+	if err := json.Unmarshal(payload.Data, out); err != nil {
+		return fmt.Errorf("failed to parse GraphQL data: %w", err)
+	}
+	return nil
+}
+
+// fetchRelationships paginates following and/or followers of login together.
+func (c *graphqlClient) fetchRelationships(ctx context.Context, login string, withFollowing, withFollowers bool) (following, followers []graphqlUser, err error) {
+	ctx, cancel := c.opts.callContext(ctx)
+	defer cancel()
+
+	var followingAfter, followersAfter *string
+	for withFollowing || withFollowers {
+		var data struct {
+			User *struct {
+				Following *graphqlConnection `json:"following"`
+				Followers *graphqlConnection `json:"followers"`
+			} `json:"user"`
+		}
+		variables := map[string]any{
+			"login":          login,
+			"followingAfter": followingAfter,
+			"followersAfter": followersAfter,
+			"withFollowing":  withFollowing,
+			"withFollowers":  withFollowers,
+		}
+		if err := c.query(ctx, relationshipsQuery, variables, &data); err != nil {
+			return nil, nil, err
+		}
+		if data.User == nil {
+			return nil, nil, fmt.Errorf("user %s not found", login)
+		}
+
+		if withFollowing {
+			conn := data.User.Following
+			if conn == nil {
+				return nil, nil, fmt.Errorf("missing following in GraphQL response")
+			}
+			following = append(following, conn.Nodes...)
+			withFollowing = conn.PageInfo.HasNextPage
+			followingAfter = &conn.PageInfo.EndCursor
+		}
+		if withFollowers {
+			conn := data.User.Followers
+			if conn == nil {
+				return nil, nil, fmt.Errorf("missing followers in GraphQL response")
+			}
+			followers = append(followers, conn.Nodes...)
+			withFollowers = conn.PageInfo.HasNextPage
+			followersAfter = &conn.PageInfo.EndCursor
+		}
+	}
+	return following, followers, nil
+}
+
+// relationship returns the requested list ("following" or "followers") of login.
+// The other list is fetched in the same requests and kept for the next call, for
+// at most prefetchTTL.
+func (c *graphqlClient) relationship(ctx context.Context, kind, login string) ([]graphqlUser, error) {
+	c.mu.Lock()
+	if l, ok := c.prefetched[kind+":"+login]; ok {
+		delete(c.prefetched, kind+":"+login)
+		if c.now().Sub(l.fetched) < prefetchTTL {
+			c.mu.Unlock()
+			return l.users, nil
+		}
+	}
+	c.mu.Unlock()
+
+	following, followers, err := c.fetchRelationships(ctx, login, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fetched := c.now()
+	if kind == "following" {
+		c.prefetched["followers:"+login] = prefetchedList{users: followers, fetched: fetched}
+		return following, nil
+	}
+	c.prefetched["following:"+login] = prefetchedList{users: following, fetched: fetched}
+	return followers, nil
+}
+
+// GetUser returns the GitHub username of the authenticated user.
+func (c *graphqlClient) GetUser(ctx context.Context) (string, error) {
+	ctx, cancel := c.opts.callContext(ctx)
+	defer cancel()
+
+	var data struct {
+		Viewer struct {
+			Login string `json:"login"`
+		} `json:"viewer"`
+	}
+	if err := c.query(ctx, viewerQuery, nil, &data); err != nil {
+		return "", fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+	if data.Viewer.Login == "" {
+		return "", fmt.Errorf("could not find authenticated user in GraphQL response")
+	}
+	return data.Viewer.Login, nil
+}
+
+// GetFollowing returns a list of users that the given user is following.
+func (c *graphqlClient) GetFollowing(ctx context.Context, user string) ([]string, error) {
+	users, err := c.GetFollowingUsers(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return Logins(users), nil
+}
+
+// GetFollowers returns a list of users that are following the given user.
+func (c *graphqlClient) GetFollowers(ctx context.Context, user string) ([]string, error) {
+	users, err := c.GetFollowersUsers(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return Logins(users), nil
+}
+
+// GetFollowingUsers returns the profiles of users that the given user is following.
+func (c *graphqlClient) GetFollowingUsers(ctx context.Context, user string) ([]User, error) {
+	users, err := c.relationship(ctx, "following", user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get following of %s via GraphQL: %w", user, err)
+	}
+	return graphqlUsers(users), nil
+}
+
+// GetFollowersUsers returns the profiles of users that are following the given user.
+func (c *graphqlClient) GetFollowersUsers(ctx context.Context, user string) ([]User, error) {
+	users, err := c.relationship(ctx, "followers", user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followers of %s via GraphQL: %w", user, err)
+	}
+	return graphqlUsers(users), nil
+}
+
+func graphqlUsers(nodes []graphqlUser) []User {
+	users := make([]User, len(nodes))
+	for i, n := range nodes {
+		users[i] = n.toUser()
+	}
+	return users
+}
@@ -0,0 +1,199 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// graphqlRequest is the body the GraphQL client posts.
+type graphqlRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+// newTestGraphQLClient starts an httptest server that answers GraphQL requests with respond.
+// The number of requests served is written to *calls.
+func newTestGraphQLClient(t *testing.T, calls *int, respond func(req graphqlRequest) string) Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req graphqlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		*calls++
+		fmt.Fprint(w, respond(req))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewGraphQLClientWithHTTP(server.Client(), server.URL, "test-token")
+	if err != nil {
+		t.Fatalf("failed to create GraphQL client: %v", err)
+	}
+	return client
+}
+
+func TestGraphQLGetUser(t *testing.T) {
+	calls := 0
+	client := newTestGraphQLClient(t, &calls, func(req graphqlRequest) string {
+		return `{"data": {"viewer": {"login": "testuser"}}}`
+	})
+
+	user, err := client.GetUser(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user != "testuser" {
+		t.Errorf("expected user 'testuser', got '%s'", user)
+	}
+}
+
+func TestGraphQLGetFollowingAndFollowers(t *testing.T) {
+	calls := 0
+	client := newTestGraphQLClient(t, &calls, func(req graphqlRequest) string {
+		if req.Variables["login"] != "testuser" {
+			t.Errorf("unexpected login %v", req.Variables["login"])
+		}
+		// Following has two pages, followers has one.
+		if req.Variables["followingAfter"] == nil {
+			return `{"data": {"user": {
+				"following": {"pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": [{"login": "alice"}, {"login": "bob"}]},
+				"followers": {"pageInfo": {"hasNextPage": false, "endCursor": "d1"}, "nodes": [{"__typename": "User", "login": "bob"}, {"__typename": "Bot", "login": "dave"}]}
+			}}}`
+		}
+		if req.Variables["followingAfter"] != "c1" || req.Variables["withFollowers"] != false {
+			t.Errorf("unexpected variables for second page: %v", req.Variables)
+		}
+		return `{"data": {"user": {
+			"following": {"pageInfo": {"hasNextPage": false, "endCursor": "c2"}, "nodes": [{"login": "charlie"}]}
+		}}}`
+	})
+
+	following, err := client.GetFollowing(context.Background(), "testuser")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	followers, err := client.GetFollowersUsers(context.Background(), "testuser")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := []string{"alice", "bob", "charlie"}; !compareStringSlices(following, expected) {
+		t.Errorf("expected following %v, got %v", expected, following)
+	}
+	if expected := []string{"bob", "dave"}; !compareStringSlices(Logins(followers), expected) {
+		t.Errorf("expected followers %v, got %v", expected, Logins(followers))
+	}
+	if followers[0].Type != "User" || followers[1].Type != "Bot" {
+		t.Errorf("expected the types of the accounts, got %q and %q", followers[0].Type, followers[1].Type)
+	}
+	if calls != 2 {
+		t.Errorf("expected both lists to be fetched in 2 requests, got %d", calls)
+	}
+}
+
+func TestGraphQLPrefetchedListServesOneCall(t *testing.T) {
+	calls := 0
+	client := newTestGraphQLClient(t, &calls, func(req graphqlRequest) string {
+		return `{"data": {"user": {
+			"following": {"pageInfo": {"hasNextPage": false, "endCursor": "c1"}, "nodes": [{"login": "alice"}]},
+			"followers": {"pageInfo": {"hasNextPage": false, "endCursor": "d1"}, "nodes": [{"login": "bob"}]}
+		}}}`
+	})
+	ctx := context.Background()
+
+	steps := []struct {
+		call  func() ([]string, error)
+		calls int
+	}{
+		{func() ([]string, error) { return client.GetFollowing(ctx, "testuser") }, 1},
+		{func() ([]string, error) { return client.GetFollowers(ctx, "other") }, 2},    // another login fetches its own lists
+		{func() ([]string, error) { return client.GetFollowers(ctx, "testuser") }, 2}, // served by the first call
+		{func() ([]string, error) { return client.GetFollowers(ctx, "testuser") }, 3}, // already consumed
+		{func() ([]string, error) { return client.GetFollowing(ctx, "testuser") }, 3}, // kept by the previous call
+	}
+	for i, step := range steps {
+		if _, err := step.call(); err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+		if calls != step.calls {
+			t.Errorf("step %d: expected %d requests in total, got %d", i, step.calls, calls)
+		}
+	}
+}
+
+func TestGraphQLPrefetchedListExpires(t *testing.T) {
+	calls := 0
+	followers := `[{"login": "bob"}]`
+	client := newTestGraphQLClient(t, &calls, func(req graphqlRequest) string {
+		return `{"data": {"user": {
+			"following": {"pageInfo": {"hasNextPage": false, "endCursor": "c1"}, "nodes": [{"login": "alice"}]},
+			"followers": {"pageInfo": {"hasNextPage": false, "endCursor": "d1"}, "nodes": ` + followers + `}
+		}}}`
+	})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	client.(*graphqlClient).now = func() time.Time { return now }
+
+	if _, err := client.GetFollowing(context.Background(), "testuser"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	followers = `[{"login": "bob"}, {"login": "carol"}]`
+	now = now.Add(prefetchTTL)
+
+	got, err := client.GetFollowers(context.Background(), "testuser")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"bob", "carol"}; !compareStringSlices(got, expected) {
+		t.Errorf("expected the followers to be fetched again, got %v", got)
+	}
+	if calls != 2 {
+		t.Errorf("expected an expired prefetched list to cost a request, got %d requests", calls)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    string
+		expectedErr string
+	}{
+		{
+			name:        "GraphQL error",
+			response:    `{"data": null, "errors": [{"message": "Could not resolve to a User with the login of 'testuser'."}]}`,
+			expectedErr: "Could not resolve to a User",
+		},
+		{
+			name:        "User not found",
+			response:    `{"data": {"user": null}}`,
+			expectedErr: "user testuser not found",
+		},
+		{
+			name:        "Invalid JSON",
+			response:    `{"data": {`,
+			expectedErr: "failed to parse GraphQL response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			client := newTestGraphQLClient(t, &calls, func(req graphqlRequest) string {
+				return tt.response
+			})
+
+			_, err := client.GetFollowers(context.Background(), "testuser")
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing '%s', got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package github
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultTimeout bounds a single client call, including every page of a paginated fetch.
+// Pauses for rate limits count towards it, so it is set high enough to wait out a secondary limit.
+const DefaultTimeout = 10 * time.Minute
+
+// DefaultMaxRetries is how many times a rate-limited request is retried.
+const DefaultMaxRetries = 3
+
+// DefaultRateLimitBackoff is the first wait after a secondary rate limit without a Retry-After header.
+const DefaultRateLimitBackoff = time.Minute
+
+// Option configures a Client.
+type Option func(*options)
+
+type options struct {
+	timeout           time.Duration
+	maxRetries        int
+	backoff           time.Duration
+	rateLimitObserver func(RateLimitEvent)
+	sleep             func(ctx context.Context, d time.Duration) error
+}
+
+// WithTimeout sets the per-call timeout. A zero or negative duration disables it.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// WithMaxRetries sets how many times a rate-limited request is retried before giving up.
+func WithMaxRetries(n int) Option {
+	return func(o *options) { o.maxRetries = n }
+}
+
+// WithRateLimitBackoff sets the initial wait for rate limits that don't say how long to wait.
+func WithRateLimitBackoff(d time.Duration) Option {
+	return func(o *options) { o.backoff = d }
+}
+
+// WithRateLimitObserver registers fn to be called with quota updates and before every rate limit pause.
+// fn may be called from any goroutine.
+func WithRateLimitObserver(fn func(RateLimitEvent)) Option {
+	return func(o *options) { o.rateLimitObserver = fn }
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		timeout:    DefaultTimeout,
+		maxRetries: DefaultMaxRetries,
+		backoff:    DefaultRateLimitBackoff,
+		sleep:      sleepContext,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// callContext derives a context for a single call, bounded by the per-call timeout.
+func (o options) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, o.timeout)
+}
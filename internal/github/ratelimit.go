@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is the API quota reported by the most recent response.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Known reports whether any quota information has been received.
+func (r RateLimit) Known() bool { return r.Limit > 0 }
+
+// RateLimitEvent is passed to the rate limit observer whenever the client
+// learns about its quota or pauses because a rate limit was hit.
+type RateLimitEvent struct {
+	RateLimit RateLimit
+	// Pause is how long the client sleeps before retrying. Zero for quota updates.
+	Pause time.Duration
+	// Until is when the pause ends.
+	Until time.Time
+}
+
+// parseRateLimit reads the X-RateLimit-* headers. The zero value is returned if they are absent.
+func parseRateLimit(h http.Header) RateLimit {
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimit{}
+	}
+	remaining, _ := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	var reset time.Time
+	if secs, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		reset = time.Unix(secs, 0)
+	}
+	return RateLimit{Limit: limit, Remaining: remaining, Reset: reset}
+}
+
+// rateLimitWait decides whether a failed response was caused by a rate limit
+// and, if so, how long to wait before retrying. attempt starts at 0.
+//
+// GitHub signals primary limits with 403/429 and X-RateLimit-Remaining: 0, and
+// secondary limits with 403/429 and an optional Retry-After header. When
+// neither header says how long to wait, the wait doubles with every attempt.
+func rateLimitWait(resp *http.Response, apiErr *APIError, attempt int, backoff time.Duration, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	rl := parseRateLimit(resp.Header)
+	if rl.Known() && rl.Remaining == 0 && !rl.Reset.IsZero() {
+		wait := rl.Reset.Sub(now) + time.Second
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || isRateLimitMessage(apiErr.Message) {
+		return backoff << attempt, true
+	}
+	return 0, false
+}
+
+// isRateLimitMessage reports whether an error message from GitHub or gh mentions a rate limit.
+func isRateLimitMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "rate limit") || strings.Contains(msg, "http 429")
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// pause notifies the observer and sleeps for d.
+func (o options) pause(ctx context.Context, rl RateLimit, d time.Duration) error {
+	if o.rateLimitObserver != nil {
+		o.rateLimitObserver(RateLimitEvent{RateLimit: rl, Pause: d, Until: time.Now().Add(d)})
+	}
+	return o.sleep(ctx, d)
+}
@@ -0,0 +1,201 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// withSleep replaces the rate limit sleep so tests don't wait in real time.
+func withSleep(fn func(ctx context.Context, d time.Duration) error) Option {
+	return func(o *options) { o.sleep = fn }
+}
+
+func TestRateLimitWait(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name         string
+		status       int
+		headers      map[string]string
+		message      string
+		attempt      int
+		expectedWait time.Duration
+		expectedHit  bool
+	}{
+		{
+			name:         "Retry-After",
+			status:       http.StatusForbidden,
+			headers:      map[string]string{"Retry-After": "30"},
+			expectedWait: 30 * time.Second,
+			expectedHit:  true,
+		},
+		{
+			name:   "Primary limit exhausted",
+			status: http.StatusForbidden,
+			headers: map[string]string{
+				"X-RateLimit-Limit":     "5000",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     strconv.FormatInt(now.Add(90*time.Second).Unix(), 10),
+			},
+			expectedWait: 91 * time.Second,
+			expectedHit:  true,
+		},
+		{
+			name:         "Secondary limit without headers backs off exponentially",
+			status:       http.StatusForbidden,
+			message:      "You have exceeded a secondary rate limit",
+			attempt:      2,
+			expectedWait: 4 * time.Minute,
+			expectedHit:  true,
+		},
+		{
+			name:         "429 without headers",
+			status:       http.StatusTooManyRequests,
+			expectedWait: time.Minute,
+			expectedHit:  true,
+		},
+		{
+			name:        "Plain forbidden",
+			status:      http.StatusForbidden,
+			message:     "Resource not accessible by integration",
+			expectedHit: false,
+		},
+		{
+			name:        "Not found",
+			status:      http.StatusNotFound,
+			headers:     map[string]string{"Retry-After": "30"},
+			expectedHit: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+			wait, hit := rateLimitWait(resp, &APIError{StatusCode: tt.status, Message: tt.message}, tt.attempt, time.Minute, now)
+			if hit != tt.expectedHit {
+				t.Fatalf("expected rate limit hit %v, got %v", tt.expectedHit, hit)
+			}
+			if wait != tt.expectedWait {
+				t.Errorf("expected wait %v, got %v", tt.expectedWait, wait)
+			}
+		})
+	}
+}
+
+func TestRESTRetriesAfterRateLimit(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		if calls == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "10")
+			w.Header().Set("Retry-After", "42")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"message": "You have exceeded a secondary rate limit"}`)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "9")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var slept []time.Duration
+	var events []RateLimitEvent
+	client, err := NewRESTClientWithHTTP(server.Client(), server.URL, "test-token",
+		withSleep(func(ctx context.Context, d time.Duration) error {
+			slept = append(slept, d)
+			return nil
+		}),
+		WithRateLimitObserver(func(ev RateLimitEvent) { events = append(events, ev) }),
+	)
+	if err != nil {
+		t.Fatalf("failed to create REST client: %v", err)
+	}
+
+	if err := client.Follow(context.Background(), "testuser"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 requests, got %d", calls)
+	}
+	if len(slept) != 1 || slept[0] != 42*time.Second {
+		t.Errorf("expected a single 42s pause, got %v", slept)
+	}
+
+	var pauses int
+	var last RateLimit
+	for _, ev := range events {
+		if ev.Pause > 0 {
+			pauses++
+		}
+		last = ev.RateLimit
+	}
+	if pauses != 1 {
+		t.Errorf("expected 1 pause event, got %d", pauses)
+	}
+	if last.Limit != 5000 || last.Remaining != 9 {
+		t.Errorf("expected last quota 9/5000, got %d/%d", last.Remaining, last.Limit)
+	}
+}
+
+func TestRESTGivesUpAfterMaxRetries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, err := NewRESTClientWithHTTP(server.Client(), server.URL, "test-token",
+		WithMaxRetries(2),
+		withSleep(func(ctx context.Context, d time.Duration) error { return nil }),
+	)
+	if err != nil {
+		t.Fatalf("failed to create REST client: %v", err)
+	}
+
+	err = client.Unfollow(context.Background(), "testuser")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected a 429 APIError, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 requests, got %d", calls)
+	}
+}
+
+func TestGhClientRetriesAfterRateLimit(t *testing.T) {
+	calls := 0
+	runner := &mockCommandRunner{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("gh: You have exceeded a secondary rate limit (HTTP 403)")
+			}
+			return nil, nil
+		},
+	}
+	var slept []time.Duration
+	client := NewClientWithRunner(runner,
+		WithRateLimitBackoff(time.Second),
+		withSleep(func(ctx context.Context, d time.Duration) error {
+			slept = append(slept, d)
+			return nil
+		}),
+	)
+
+	if err := client.Follow(context.Background(), "testuser"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 || len(slept) != 1 || slept[0] != time.Second {
+		t.Errorf("expected one retry after 1s, got %d calls and pauses %v", calls, slept)
+	}
+}
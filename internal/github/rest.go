@@ -0,0 +1,371 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the root of the public GitHub REST API.
+const DefaultBaseURL = "https://api.github.com/"
+
+// perPage is the page size requested from list endpoints. 100 is the maximum GitHub allows.
+const perPage = 100
+
+// linkNextRe extracts the rel="next" URL from a Link response header.
+var linkNextRe = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// APIError is returned when the GitHub API responds with a non-success status code.
+type APIError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// restClient is an implementation of the Client interface that talks to the
+// GitHub REST API directly over net/http, without requiring the gh CLI.
+type restClient struct {
+	httpClient *http.Client
+	baseURL    *url.URL
+	token      string
+	opts       options
+	pages      *pageCache // list pages with their ETag, for conditional requests
+}
+
+// newRESTClient returns a REST client for baseURL.
+func newRESTClient(httpClient *http.Client, baseURL *url.URL, token string, opts []Option) *restClient {
+	return &restClient{httpClient: httpClient, baseURL: baseURL, token: token, opts: newOptions(opts), pages: newPageCache()}
+}
+
+// cachedPage is a page of a user list with the ETag it was served with.
+type cachedPage struct {
+	etag  string
+	users []User
+	next  string
+}
+
+// pageCache holds the list pages fetched so far. Fetching a page again sends its
+// ETag in If-None-Match: GitHub answers 304 Not Modified without a body and without
+// counting the request against the rate limit when the page did not change.
+type pageCache struct {
+	mu    sync.Mutex
+	pages map[string]cachedPage // request URL -> page
+}
+
+func newPageCache() *pageCache {
+	return &pageCache{pages: make(map[string]cachedPage)}
+}
+
+func (c *pageCache) get(u string) (cachedPage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pages[u]
+	return p, ok
+}
+
+func (c *pageCache) put(u string, p cachedPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pages[u] = p
+}
+
+// NewRESTClient creates a Client that uses the GitHub REST API with the given token.
+func NewRESTClient(token string, opts ...Option) Client {
+	c, _ := NewRESTClientWithHTTP(http.DefaultClient, DefaultBaseURL, token, opts...)
+	return c
+}
+
+// NewRESTClientWithHTTP creates a REST Client with a custom HTTP client and base URL.
+// It is used for GitHub Enterprise hosts and for testing against an httptest server.
+func NewRESTClientWithHTTP(httpClient *http.Client, baseURL, token string, opts ...Option) (Client, error) {
+	u, err := parseBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return newRESTClient(httpClient, u, token, opts), nil
+}
+
+// TokenFromEnv returns the GitHub token from GH_TOKEN or GITHUB_TOKEN, in that order.
+func TokenFromEnv() string {
+	if t := os.Getenv("GH_TOKEN"); t != "" {
+		return t
+	}
+	return os.Getenv("GITHUB_TOKEN")
+}
+
+func parseBaseURL(baseURL string) (*url.URL, error) {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	return u, nil
+}
+
+// do sends a request to the given API path (or absolute URL) and returns the response.
+// The caller is responsible for closing the response body.
+func (c *restClient) do(ctx context.Context, method, path string) (*http.Response, error) {
+	return c.doIfNoneMatch(ctx, method, path, "")
+}
+
+// doIfNoneMatch is do with an If-None-Match header when etag is set. A 304 Not
+// Modified response is then returned as is rather than as an error.
+func (c *restClient) doIfNoneMatch(ctx context.Context, method, path, etag string) (*http.Response, error) {
+	u, err := c.baseURL.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request path %q: %w", path, err)
+	}
+	return c.send(ctx, method, u.String(), nil, etag)
+}
+
+// send performs an HTTP request, reporting the quota to the rate limit observer
+// and sleeping and retrying when a rate limit is hit. A non-2xx response is
+// returned as an *APIError, except 304 Not Modified when etag is set.
+func (c *restClient) send(ctx context.Context, method, rawURL string, body []byte, etag string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, rawURL, bodyReader)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		rl := parseRateLimit(resp.Header)
+		if rl.Known() && c.opts.rateLimitObserver != nil {
+			c.opts.rateLimitObserver(RateLimitEvent{RateLimit: rl})
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 || etag != "" && resp.StatusCode == http.StatusNotModified {
+			return resp, nil
+		}
+
+		apiErr := newAPIError(req, resp)
+		resp.Body.Close()
+		wait, limited := rateLimitWait(resp, apiErr, attempt, c.opts.backoff, time.Now())
+		if !limited || attempt >= c.opts.maxRetries {
+			return nil, apiErr
+		}
+		if err := c.opts.pause(ctx, rl, wait); err != nil {
+			return nil, fmt.Errorf("%w (while waiting for rate limit: %w)", apiErr, err)
+		}
+	}
+}
+
+// newAPIError builds an APIError from a failed response, using the message from the JSON body if present.
+func newAPIError(req *http.Request, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	var payload struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Message:    payload.Message,
+	}
+}
+
+// getUsers fetches every page of a user list endpoint, following the Link header.
+// Pages fetched before are requested with their ETag and reused when unchanged.
+func (c *restClient) getUsers(ctx context.Context, path string) ([]User, error) {
+	ctx, cancel := c.opts.callContext(ctx)
+	defer cancel()
+
+	var all []User
+	next := fmt.Sprintf("%s?per_page=%d", path, perPage)
+	for next != "" {
+		cached, _ := c.pages.get(next)
+		resp, err := c.doIfNoneMatch(ctx, http.MethodGet, next, cached.etag)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotModified {
+			resp.Body.Close()
+			all = append(all, cached.users...)
+			next = cached.next
+			continue
+		}
+
+		var users []User
+		err = json.NewDecoder(resp.Body).Decode(&users)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JSON from %s: %w", path, err)
+		}
+
+		page := cachedPage{etag: resp.Header.Get("ETag"), users: users, next: nextPageURL(resp.Header.Get("Link"))}
+		if page.etag != "" {
+			c.pages.put(next, page)
+		}
+		all = append(all, users...)
+		next = page.next
+	}
+	return all, nil
+}
+
+// nextPageURL returns the rel="next" URL from a Link header, or "" if there is none.
+func nextPageURL(link string) string {
+	matches := linkNextRe.FindStringSubmatch(link)
+	if len(matches) < 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// GetUser returns the GitHub username of the authenticated user.
+func (c *restClient) GetUser(ctx context.Context) (string, error) {
+	ctx, cancel := c.opts.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.do(ctx, http.MethodGet, "user")
+	if err != nil {
+		return "", fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var u User
+	if err := json.NewDecoder(resp.Body).Decode(&u); err != nil {
+		return "", fmt.Errorf("failed to parse JSON from user: %w", err)
+	}
+	if u.Login == "" {
+		return "", fmt.Errorf("could not find authenticated user in API response")
+	}
+	return u.Login, nil
+}
+
+// GetFollowing returns a list of users that the given user is following.
+func (c *restClient) GetFollowing(ctx context.Context, user string) ([]string, error) {
+	users, err := c.GetFollowingUsers(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return Logins(users), nil
+}
+
+// GetFollowers returns a list of users that are following the given user.
+func (c *restClient) GetFollowers(ctx context.Context, user string) ([]string, error) {
+	users, err := c.GetFollowersUsers(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return Logins(users), nil
+}
+
+// GetFollowingUsers returns the profiles of users that the given user is following.
+func (c *restClient) GetFollowingUsers(ctx context.Context, user string) ([]User, error) {
+	following, err := c.getUsers(ctx, "users/"+url.PathEscape(user)+"/following")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users/%s/following: %w", user, err)
+	}
+	return following, nil
+}
+
+// GetFollowersUsers returns the profiles of users that are following the given user.
+func (c *restClient) GetFollowersUsers(ctx context.Context, user string) ([]User, error) {
+	followers, err := c.getUsers(ctx, "users/"+url.PathEscape(user)+"/followers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users/%s/followers: %w", user, err)
+	}
+	return followers, nil
+}
+
+// GetUserProfile returns the full profile of the given user.
+func (c *restClient) GetUserProfile(ctx context.Context, login string) (User, error) {
+	ctx, cancel := c.opts.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.do(ctx, http.MethodGet, "users/"+url.PathEscape(login))
+	if err != nil {
+		return User{}, fmt.Errorf("failed to get users/%s: %w", login, err)
+	}
+	defer resp.Body.Close()
+
+	var u User
+	if err := json.NewDecoder(resp.Body).Decode(&u); err != nil {
+		return User{}, fmt.Errorf("failed to parse JSON from users/%s: %w", login, err)
+	}
+	return u, nil
+}
+
+// GetLastActivity returns the time of the latest public event of the given user.
+func (c *restClient) GetLastActivity(ctx context.Context, login string) (time.Time, error) {
+	ctx, cancel := c.opts.callContext(ctx)
+	defer cancel()
+
+	path := "users/" + url.PathEscape(login) + "/events/public?per_page=1"
+	resp, err := c.do(ctx, http.MethodGet, path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get users/%s/events/public: %w", login, err)
+	}
+	defer resp.Body.Close()
+
+	var events []publicEvent
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse JSON from users/%s/events/public: %w", login, err)
+	}
+	return latestEvent(events), nil
+}
+
+// Unfollow unfollows a given user.
+func (c *restClient) Unfollow(ctx context.Context, user string) error {
+	ctx, cancel := c.opts.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.do(ctx, http.MethodDelete, "user/following/"+url.PathEscape(user))
+	if err != nil {
+		return fmt.Errorf("failed to unfollow %s: %w", user, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Follow follows a given user.
+func (c *restClient) Follow(ctx context.Context, user string) error {
+	ctx, cancel := c.opts.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.do(ctx, http.MethodPut, "user/following/"+url.PathEscape(user))
+	if err != nil {
+		return fmt.Errorf("failed to follow %s: %w", user, err)
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,396 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestRESTClient starts an httptest server with the given handler and returns a REST client pointed at it.
+func newTestRESTClient(t *testing.T, handler http.HandlerFunc) Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewRESTClientWithHTTP(server.Client(), server.URL, "test-token")
+	if err != nil {
+		t.Fatalf("failed to create REST client: %v", err)
+	}
+	return client
+}
+
+func TestRESTGetUser(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		expectedUser string
+		expectedErr  string
+	}{
+		{
+			name:         "Success",
+			status:       http.StatusOK,
+			body:         `{"login": "testuser"}`,
+			expectedUser: "testuser",
+		},
+		{
+			name:        "Unauthorized",
+			status:      http.StatusUnauthorized,
+			body:        `{"message": "Bad credentials"}`,
+			expectedErr: "Bad credentials",
+		},
+		{
+			name:        "Missing login",
+			status:      http.StatusOK,
+			body:        `{}`,
+			expectedErr: "could not find authenticated user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestRESTClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/user" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+					t.Errorf("unexpected Authorization header %q", got)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			user, err := client.GetUser(context.Background())
+
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("expected error containing '%s', got '%v'", tt.expectedErr, err)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if user != tt.expectedUser {
+					t.Errorf("expected user '%s', got '%s'", tt.expectedUser, user)
+				}
+			}
+		})
+	}
+}
+
+func TestRESTGetFollowing_Pagination(t *testing.T) {
+	var serverURL string
+	client := newTestRESTClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/testuser/following" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/users/testuser/following?per_page=100&page=2>; rel="next", <%s/users/testuser/following?per_page=100&page=2>; rel="last"`, serverURL, serverURL))
+			fmt.Fprint(w, `[{"login": "alice"}, {"login": "bob"}]`)
+		case "2":
+			fmt.Fprint(w, `[{"login": "charlie"}]`)
+		default:
+			t.Errorf("unexpected page %s", r.URL.Query().Get("page"))
+		}
+	})
+	serverURL = strings.TrimSuffix(client.(*restClient).baseURL.String(), "/")
+
+	following, err := client.GetFollowing(context.Background(), "testuser")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"alice", "bob", "charlie"}
+	if !compareStringSlices(following, expected) {
+		t.Errorf("expected %v, got %v", expected, following)
+	}
+}
+
+func TestRESTGetFollowers(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "Success",
+			status:   http.StatusOK,
+			body:     `[{"login": "charlie"}, {"login": "dave"}]`,
+			expected: []string{"charlie", "dave"},
+		},
+		{
+			name:        "Not found",
+			status:      http.StatusNotFound,
+			body:        `{"message": "Not Found"}`,
+			expectedErr: "failed to get users/testuser/followers",
+		},
+		{
+			name:        "Invalid JSON",
+			status:      http.StatusOK,
+			body:        `[{"login": "charlie"`,
+			expectedErr: "failed to parse JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestRESTClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/users/testuser/followers" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			followers, err := client.GetFollowers(context.Background(), "testuser")
+
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("expected error containing '%s', got '%v'", tt.expectedErr, err)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if !compareStringSlices(followers, tt.expected) {
+					t.Errorf("expected %v, got %v", tt.expected, followers)
+				}
+			}
+		})
+	}
+}
+
+func TestRESTFollowAndUnfollow(t *testing.T) {
+	tests := []struct {
+		name           string
+		action         func(c Client) error
+		status         int
+		expectedMethod string
+		expectedErr    string
+	}{
+		{
+			name:           "Follow success",
+			action:         func(c Client) error { return c.Follow(context.Background(), "testuser") },
+			status:         http.StatusNoContent,
+			expectedMethod: http.MethodPut,
+		},
+		{
+			name:           "Follow forbidden",
+			action:         func(c Client) error { return c.Follow(context.Background(), "testuser") },
+			status:         http.StatusForbidden,
+			expectedMethod: http.MethodPut,
+			expectedErr:    "failed to follow testuser",
+		},
+		{
+			name:           "Unfollow success",
+			action:         func(c Client) error { return c.Unfollow(context.Background(), "testuser") },
+			status:         http.StatusNoContent,
+			expectedMethod: http.MethodDelete,
+		},
+		{
+			name:           "Unfollow not found",
+			action:         func(c Client) error { return c.Unfollow(context.Background(), "testuser") },
+			status:         http.StatusNotFound,
+			expectedMethod: http.MethodDelete,
+			expectedErr:    "failed to unfollow testuser",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestRESTClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/user/following/testuser" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if r.Method != tt.expectedMethod {
+					t.Errorf("expected method %s, got %s", tt.expectedMethod, r.Method)
+				}
+				w.WriteHeader(tt.status)
+			})
+
+			err := tt.action(client)
+
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("expected error containing '%s', got '%v'", tt.expectedErr, err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		link     string
+		expected string
+	}{
+		{
+			name:     "Has next",
+			link:     `<https://api.github.com/users/x/followers?page=2>; rel="next", <https://api.github.com/users/x/followers?page=5>; rel="last"`,
+			expected: "https://api.github.com/users/x/followers?page=2",
+		},
+		{
+			name:     "Last page",
+			link:     `<https://api.github.com/users/x/followers?page=1>; rel="first", <https://api.github.com/users/x/followers?page=4>; rel="prev"`,
+			expected: "",
+		},
+		{
+			name:     "No header",
+			link:     "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextPageURL(tt.link); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRESTGetUserProfile(t *testing.T) {
+	client := newTestRESTClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/octocat" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"login": "octocat", "name": "The Octocat", "company": "@github", "location": "San Francisco",
+			"bio": null, "followers": 20, "following": 9, "public_repos": 8, "created_at": "2011-01-25T18:44:36Z",
+			"type": "User", "site_admin": false}`)
+	})
+
+	u, err := client.GetUserProfile(context.Background(), "octocat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u.Name != "The Octocat" || u.Company != "@github" || u.Followers != 20 || u.PublicRepos != 8 || u.Type != "User" {
+		t.Errorf("unexpected profile %+v", u)
+	}
+	if u.CreatedAt.Year() != 2011 {
+		t.Errorf("expected created_at in 2011, got %v", u.CreatedAt)
+	}
+}
+
+func TestRESTGetLastActivity(t *testing.T) {
+	client := newTestRESTClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/octocat/events/public" || r.URL.Query().Get("per_page") != "1" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		fmt.Fprint(w, `[{"type": "PushEvent", "created_at": "2024-05-01T12:00:00Z"}]`)
+	})
+
+	last, err := client.GetLastActivity(context.Background(), "octocat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC); !last.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, last)
+	}
+}
+
+func TestRESTCancellation(t *testing.T) {
+	release := make(chan struct{})
+	client := newTestRESTClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	})
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.GetFollowers(ctx, "testuser")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRESTTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewRESTClientWithHTTP(server.Client(), server.URL, "test-token", WithTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create REST client: %v", err)
+	}
+
+	err = client.Follow(context.Background(), "testuser")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRESTGetFollowersUsers_ConditionalRequests(t *testing.T) {
+	var mu sync.Mutex
+	pages := map[string]string{"1": `[{"login": "alice"}]`, "2": `[{"login": "bob"}]`}
+	var notModified int
+	var serverURL string
+	client := newTestRESTClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		etag := fmt.Sprintf(`"%s-%d"`, page, len(pages[page]))
+		if page == "1" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/users/me/followers?per_page=100&page=2>; rel="next"`, serverURL))
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, pages[page])
+	})
+	serverURL = strings.TrimSuffix(client.(*restClient).baseURL.String(), "/")
+
+	for i, want := range []struct {
+		logins      []string
+		notModified int
+	}{
+		{[]string{"alice", "bob"}, 0},
+		{[]string{"alice", "bob"}, 2},          // both pages revalidated
+		{[]string{"alice", "bob", "carol"}, 3}, // only page 2 changed
+	} {
+		if i == 2 {
+			mu.Lock()
+			pages["2"] = `[{"login": "bob"}, {"login": "carol"}]`
+			mu.Unlock()
+		}
+		users, err := client.GetFollowersUsers(context.Background(), "me")
+		if err != nil {
+			t.Fatalf("fetch %d: unexpected error: %v", i, err)
+		}
+		if got := Logins(users); strings.Join(got, ",") != strings.Join(want.logins, ",") {
+			t.Errorf("fetch %d: expected %v, got %v", i, want.logins, got)
+		}
+		mu.Lock()
+		if notModified != want.notModified {
+			t.Errorf("fetch %d: expected %d not modified responses, got %d", i, want.notModified, notModified)
+		}
+		mu.Unlock()
+	}
+}
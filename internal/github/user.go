@@ -0,0 +1,46 @@
+package github
+
+import "time"
+
+// User is a GitHub account profile.
+// List endpoints only fill in a subset of the fields (login, avatar, type, site admin);
+// GetUserProfile returns the full profile.
+type User struct {
+	Login       string    `json:"login"`
+	Name        string    `json:"name"`
+	AvatarURL   string    `json:"avatar_url"`
+	Bio         string    `json:"bio"`
+	Company     string    `json:"company"`
+	Location    string    `json:"location"`
+	Followers   int       `json:"followers"`
+	Following   int       `json:"following"`
+	PublicRepos int       `json:"public_repos"`
+	CreatedAt   time.Time `json:"created_at"`
+	Type        string    `json:"type"` // "User" or "Organization"
+	SiteAdmin   bool      `json:"site_admin"`
+}
+
+// publicEvent is the part of a GitHub event used to find a user's last activity.
+type publicEvent struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// latestEvent returns the time of the most recent event, or the zero time when there is none.
+func latestEvent(events []publicEvent) time.Time {
+	var latest time.Time
+	for _, e := range events {
+		if e.CreatedAt.After(latest) {
+			latest = e.CreatedAt
+		}
+	}
+	return latest
+}
+
+// Logins returns the login of each user, in order.
+func Logins(users []User) []string {
+	logins := make([]string, len(users))
+	for i, u := range users {
+		logins[i] = u.Login
+	}
+	return logins
+}
@@ -0,0 +1,234 @@
+// Package history keeps timestamped snapshots of who an account follows and is
+// followed by, so changes between two points in time can be reported.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gh-mutual-follow/internal/logins"
+	"gh-mutual-follow/internal/xdg"
+)
+
+// Snapshot is the following and followers of an account at a point in time.
+type Snapshot struct {
+	Time      time.Time `json:"time"`
+	Account   string    `json:"account"`
+	Following []string  `json:"following"`
+	Followers []string  `json:"followers"`
+}
+
+// NewSnapshot returns a snapshot of account taken now, with sorted lists.
+func NewSnapshot(account string, following, followers []string) Snapshot {
+	return Snapshot{
+		Time:      time.Now().UTC(),
+		Account:   account,
+		Following: logins.Sorted(following),
+		Followers: logins.Sorted(followers),
+	}
+}
+
+// SameLists reports whether s and o have the same following and followers.
+func (s Snapshot) SameLists(o Snapshot) bool {
+	return slices.Equal(s.Following, o.Following) && slices.Equal(s.Followers, o.Followers)
+}
+
+// Diff is what changed between two snapshots of an account.
+type Diff struct {
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	NewFollowers   []string  `json:"new_followers"`
+	LostFollowers  []string  `json:"lost_followers"`
+	NewFollowing   []string  `json:"new_following"`
+	EndedFollowing []string  `json:"ended_following"`
+}
+
+// Compare returns the changes from snapshot from to snapshot to.
+func Compare(from, to Snapshot) Diff {
+	d := Diff{From: from.Time, To: to.Time}
+	d.NewFollowers, d.LostFollowers = logins.Difference(from.Followers, to.Followers)
+	d.NewFollowing, d.EndedFollowing = logins.Difference(from.Following, to.Following)
+	return d
+}
+
+// Empty reports whether nothing changed.
+func (d Diff) Empty() bool {
+	return len(d.NewFollowers)+len(d.LostFollowers)+len(d.NewFollowing)+len(d.EndedFollowing) == 0
+}
+
+// WriteDiff writes a human readable report of the changes.
+func (d Diff) WriteDiff(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Changes from %s to %s\n\n", d.From.Local().Format("2006-01-02 15:04"), d.To.Local().Format("2006-01-02 15:04"))
+	for _, login := range d.NewFollowers {
+		fmt.Fprintf(&b, "+ new follower      %s\n", login)
+	}
+	for _, login := range d.LostFollowers {
+		fmt.Fprintf(&b, "- lost follower     %s\n", login)
+	}
+	for _, login := range d.NewFollowing {
+		fmt.Fprintf(&b, "+ started following %s\n", login)
+	}
+	for _, login := range d.EndedFollowing {
+		fmt.Fprintf(&b, "- stopped following %s\n", login)
+	}
+	if d.Empty() {
+		b.WriteString("No changes.\n")
+	} else {
+		fmt.Fprintf(&b, "\n%d new followers, %d lost followers, %d started following, %d stopped following.\n",
+			len(d.NewFollowers), len(d.LostFollowers), len(d.NewFollowing), len(d.EndedFollowing))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Store keeps the snapshots of accounts. Snapshots of an account are appended in time order.
+type Store interface {
+	// Append adds s, unless the lists are the same as in the latest snapshot of the account.
+	Append(s Snapshot) error
+	// Latest returns the most recent snapshot of account, if any.
+	Latest(account string) (Snapshot, bool, error)
+	// Baseline returns the latest snapshot of account taken at or before t, or else the oldest one.
+	Baseline(account string, t time.Time) (Snapshot, bool, error)
+}
+
+// Log is a JSON Lines file of snapshots. It is safe for concurrent use.
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLog returns the snapshot log stored at path. The file is created on the first Append.
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// DefaultPath returns the log location under the XDG state directory:
+// $XDG_STATE_HOME/gh-mutual-follow/snapshots.jsonl, or ~/.local/state/... when unset.
+func DefaultPath() (string, error) {
+	return xdg.StatePath("snapshots.jsonl")
+}
+
+// Path returns the location of the log file.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append adds s to the log, unless the lists are the same as in the latest snapshot
+// of the account: an unchanged snapshot tells nothing new and would only grow the file.
+func (l *Log) Append(s Snapshot) error {
+	latest, ok, err := l.Latest(s.Account)
+	if err != nil {
+		return err
+	}
+	if ok && latest.SameLists(s) {
+		return nil
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write snapshot log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot log: %w", err)
+	}
+	return nil
+}
+
+// Snapshots returns the snapshots of account, oldest first. A missing log has no snapshots.
+func (l *Log) Snapshots(account string) ([]Snapshot, error) {
+	all, err := l.All()
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []Snapshot
+	for _, s := range all {
+		if s.Account == account {
+			snapshots = append(snapshots, s)
+		}
+	}
+	return snapshots, nil
+}
+
+// All returns the snapshots of every account, oldest first. A missing log has no snapshots.
+func (l *Log) All() ([]Snapshot, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.Open(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot log: %w", err)
+	}
+	defer f.Close()
+
+	var snapshots []Snapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // a snapshot holds whole lists
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var s Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot log line %d: %w", line, err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot log: %w", err)
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].Time.Before(snapshots[j].Time) })
+	return snapshots, nil
+}
+
+// Latest returns the most recent snapshot of account, if any.
+func (l *Log) Latest(account string) (Snapshot, bool, error) {
+	snapshots, err := l.Snapshots(account)
+	if err != nil || len(snapshots) == 0 {
+		return Snapshot{}, false, err
+	}
+	return snapshots[len(snapshots)-1], true, nil
+}
+
+// Baseline returns the snapshot of account to compare with when asking what changed
+// since t: the latest one taken at or before t, or else the oldest one there is.
+func (l *Log) Baseline(account string, t time.Time) (Snapshot, bool, error) {
+	snapshots, err := l.Snapshots(account)
+	if err != nil || len(snapshots) == 0 {
+		return Snapshot{}, false, err
+	}
+	baseline := snapshots[0]
+	for _, s := range snapshots {
+		if s.Time.After(t) {
+			break
+		}
+		baseline = s
+	}
+	return baseline, true, nil
+}
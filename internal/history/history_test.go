@@ -0,0 +1,115 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLog(t *testing.T) *Log {
+	t.Helper()
+	return NewLog(filepath.Join(t.TempDir(), "state", "snapshots.jsonl"))
+}
+
+func snapshotAt(at time.Time, account string, following, followers []string) Snapshot {
+	s := NewSnapshot(account, following, followers)
+	s.Time = at
+	return s
+}
+
+func TestCompare(t *testing.T) {
+	from := snapshotAt(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), "me", []string{"alice", "bob"}, []string{"carol", "dave"})
+	to := snapshotAt(time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC), "me", []string{"bob", "erin"}, []string{"dave", "frank", "alice"})
+
+	d := Compare(from, to)
+	if !reflect.DeepEqual(d.NewFollowers, []string{"alice", "frank"}) {
+		t.Errorf("unexpected new followers %v", d.NewFollowers)
+	}
+	if !reflect.DeepEqual(d.LostFollowers, []string{"carol"}) {
+		t.Errorf("unexpected lost followers %v", d.LostFollowers)
+	}
+	if !reflect.DeepEqual(d.NewFollowing, []string{"erin"}) {
+		t.Errorf("unexpected new following %v", d.NewFollowing)
+	}
+	if !reflect.DeepEqual(d.EndedFollowing, []string{"alice"}) {
+		t.Errorf("unexpected ended following %v", d.EndedFollowing)
+	}
+	if !d.From.Equal(from.Time) || !d.To.Equal(to.Time) {
+		t.Errorf("unexpected period %s - %s", d.From, d.To)
+	}
+
+	var b strings.Builder
+	if err := d.WriteDiff(&b); err != nil {
+		t.Fatalf("failed to write diff: %v", err)
+	}
+	for _, want := range []string{
+		"+ new follower      alice\n",
+		"- lost follower     carol\n",
+		"+ started following erin\n",
+		"- stopped following alice\n",
+		"2 new followers, 1 lost followers, 1 started following, 1 stopped following.",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("diff does not contain %q:\n%s", want, b.String())
+		}
+	}
+
+	if d := Compare(to, to); !d.Empty() {
+		t.Errorf("expected no changes, got %+v", d)
+	}
+}
+
+func TestLog_AppendAndBaseline(t *testing.T) {
+	log := newTestLog(t)
+	day := func(n int) time.Time { return time.Date(2024, 5, n, 12, 0, 0, 0, time.UTC) }
+
+	if _, ok, err := log.Baseline("me", day(1)); err != nil || ok {
+		t.Fatalf("expected no baseline in a missing log, got ok=%v err=%v", ok, err)
+	}
+
+	for _, s := range []Snapshot{
+		snapshotAt(day(1), "me", []string{"alice"}, nil),
+		snapshotAt(day(2), "me", []string{"alice"}, nil), // unchanged: not stored
+		snapshotAt(day(3), "other", []string{"zed"}, nil),
+		snapshotAt(day(5), "me", []string{"alice", "bob"}, []string{"carol"}),
+		snapshotAt(day(9), "me", []string{"bob"}, []string{"carol"}),
+	} {
+		if err := log.Append(s); err != nil {
+			t.Fatalf("failed to append: %v", err)
+		}
+	}
+
+	snapshots, err := log.Snapshots("me")
+	if err != nil {
+		t.Fatalf("failed to read snapshots: %v", err)
+	}
+	if len(snapshots) != 3 {
+		t.Fatalf("expected 3 snapshots of me, got %d", len(snapshots))
+	}
+
+	tests := []struct {
+		since time.Time
+		want  time.Time
+	}{
+		{day(6), day(5)},  // the latest one at or before
+		{day(5), day(5)},  // inclusive
+		{day(30), day(9)}, // the most recent one
+		{day(0), day(1)},  // nothing that old: the oldest one
+	}
+	for _, tt := range tests {
+		s, ok, err := log.Baseline("me", tt.since)
+		if err != nil || !ok {
+			t.Fatalf("Baseline(%s): ok=%v err=%v", tt.since, ok, err)
+		}
+		if !s.Time.Equal(tt.want) {
+			t.Errorf("Baseline(%s) = snapshot of %s, want %s", tt.since, s.Time, tt.want)
+		}
+	}
+
+	latest, ok, err := log.Latest("me")
+	if err != nil || !ok || !latest.Time.Equal(day(9)) {
+		t.Errorf("unexpected latest snapshot %+v (ok=%v err=%v)", latest, ok, err)
+	}
+}
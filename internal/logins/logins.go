@@ -0,0 +1,43 @@
+// Package logins provides the set operations on lists of GitHub logins shared by
+// snapshots, plans and the configuration.
+package logins
+
+import (
+	"slices"
+	"sort"
+)
+
+// Sorted returns a sorted copy of logins without duplicates, never nil.
+func Sorted(logins []string) []string {
+	s := slices.Clone(logins)
+	sort.Strings(s)
+	s = slices.Compact(s)
+	if s == nil {
+		s = []string{}
+	}
+	return s
+}
+
+// Difference returns the logins of to missing from from (added) and of from missing
+// from to (removed), sorted.
+func Difference(from, to []string) (added, removed []string) {
+	inFrom := make(map[string]bool, len(from))
+	for _, login := range from {
+		inFrom[login] = true
+	}
+	inTo := make(map[string]bool, len(to))
+	for _, login := range to {
+		inTo[login] = true
+	}
+	for _, login := range Sorted(to) {
+		if !inFrom[login] {
+			added = append(added, login)
+		}
+	}
+	for _, login := range Sorted(from) {
+		if !inTo[login] {
+			removed = append(removed, login)
+		}
+	}
+	return added, removed
+}
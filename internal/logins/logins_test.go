@@ -0,0 +1,28 @@
+package logins
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSorted(t *testing.T) {
+	if got := Sorted([]string{"carol", "alice", "carol", "bob"}); !reflect.DeepEqual(got, []string{"alice", "bob", "carol"}) {
+		t.Errorf("unexpected sorted logins %v", got)
+	}
+	if got := Sorted(nil); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty, non-nil list, got %#v", got)
+	}
+}
+
+func TestDifference(t *testing.T) {
+	added, removed := Difference([]string{"bob", "alice"}, []string{"erin", "bob", "dave"})
+	if !reflect.DeepEqual(added, []string{"dave", "erin"}) {
+		t.Errorf("unexpected added %v", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"alice"}) {
+		t.Errorf("unexpected removed %v", removed)
+	}
+	if added, removed := Difference([]string{"alice"}, []string{"alice"}); added != nil || removed != nil {
+		t.Errorf("expected no difference, got %v / %v", added, removed)
+	}
+}
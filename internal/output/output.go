@@ -0,0 +1,180 @@
+// Package output writes relationship lists in machine-readable formats.
+//
+// Every format emits the same records with the same fields, in this order:
+//
+//	login         GitHub login
+//	category      following-only, followers-only or mutual
+//	name          display name
+//	type          User or Organization
+//	site_admin    true for GitHub staff
+//	company       company
+//	location      location
+//	bio           bio
+//	followers     number of followers
+//	following     number of users followed
+//	public_repos  number of public repositories
+//	created_at    account creation time in RFC 3339, empty if unknown
+//	avatar_url    avatar image URL
+//
+// Profile fields the backend did not return are empty strings or 0. JSON and
+// YAML emit a list of objects with these keys; CSV and TSV emit a header row
+// with these names; Markdown emits a table with these columns. The text format
+// prints only the logins, one per line.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{"text", "json", "csv", "tsv", "yaml", "markdown"}
+
+// Record is a single user in list output.
+type Record struct {
+	Login       string `json:"login" yaml:"login"`
+	Category    string `json:"category" yaml:"category"`
+	Name        string `json:"name" yaml:"name"`
+	Type        string `json:"type" yaml:"type"`
+	SiteAdmin   bool   `json:"site_admin" yaml:"site_admin"`
+	Company     string `json:"company" yaml:"company"`
+	Location    string `json:"location" yaml:"location"`
+	Bio         string `json:"bio" yaml:"bio"`
+	Followers   int    `json:"followers" yaml:"followers"`
+	Following   int    `json:"following" yaml:"following"`
+	PublicRepos int    `json:"public_repos" yaml:"public_repos"`
+	CreatedAt   string `json:"created_at" yaml:"created_at"`
+	AvatarURL   string `json:"avatar_url" yaml:"avatar_url"`
+}
+
+// columns are the field names, in schema order, used as CSV/TSV headers and Markdown columns.
+var columns = []string{
+	"login", "category", "name", "type", "site_admin", "company", "location", "bio",
+	"followers", "following", "public_repos", "created_at", "avatar_url",
+}
+
+// NewRecord creates the record of a user in the given category.
+func NewRecord(u github.User, category string) Record {
+	r := Record{
+		Login:       u.Login,
+		Category:    category,
+		Name:        u.Name,
+		Type:        u.Type,
+		SiteAdmin:   u.SiteAdmin,
+		Company:     u.Company,
+		Location:    u.Location,
+		Bio:         u.Bio,
+		Followers:   u.Followers,
+		Following:   u.Following,
+		PublicRepos: u.PublicRepos,
+		AvatarURL:   u.AvatarURL,
+	}
+	if !u.CreatedAt.IsZero() {
+		r.CreatedAt = u.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	return r
+}
+
+// values returns the fields of r in schema order.
+func (r Record) values() []string {
+	return []string{
+		r.Login, r.Category, r.Name, r.Type, strconv.FormatBool(r.SiteAdmin), r.Company, r.Location, r.Bio,
+		strconv.Itoa(r.Followers), strconv.Itoa(r.Following), strconv.Itoa(r.PublicRepos), r.CreatedAt, r.AvatarURL,
+	}
+}
+
+// Write writes records to w in the given format.
+func Write(w io.Writer, format string, records []Record) error {
+	switch format {
+	case "text":
+		for _, r := range records {
+			if _, err := fmt.Fprintln(w, r.Login); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		if records == nil {
+			records = []Record{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case "csv":
+		return writeCSV(w, records)
+	case "tsv":
+		return writeTSV(w, records)
+	case "yaml":
+		if records == nil {
+			records = []Record{}
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(records); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "markdown":
+		return writeMarkdown(w, records)
+	default:
+		return fmt.Errorf("unknown output format %q (want one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// writeCSV writes an RFC 4180 header row followed by one row per record.
+func writeCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := cw.Write(r.values()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeTSV writes a header row followed by one row per record. Fields are not quoted;
+// tabs and newlines inside them are replaced by spaces so every record stays on one line.
+func writeTSV(w io.Writer, records []Record) error {
+	clean := strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ")
+	var b strings.Builder
+	b.WriteString(strings.Join(columns, "\t") + "\n")
+	for _, r := range records {
+		values := r.values()
+		for i, v := range values {
+			values[i] = clean.Replace(v)
+		}
+		b.WriteString(strings.Join(values, "\t") + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdown writes the records as a GitHub Flavored Markdown table.
+func writeMarkdown(w io.Writer, records []Record) error {
+	escape := strings.NewReplacer("|", `\|`, "\t", " ", "\r\n", " ", "\n", " ")
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for _, r := range records {
+		values := r.values()
+		for i, v := range values {
+			values[i] = escape.Replace(v)
+		}
+		b.WriteString("| " + strings.Join(values, " | ") + " |\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func testRecords() []Record {
+	return []Record{
+		NewRecord(github.User{
+			Login:       "octocat",
+			Name:        "The Octocat",
+			Type:        "User",
+			Company:     "@github",
+			Location:    "San Francisco",
+			Bio:         "Loves | pipes, \"quotes\"\tand tabs",
+			Followers:   20,
+			Following:   9,
+			PublicRepos: 8,
+			CreatedAt:   time.Date(2011, 1, 25, 18, 44, 36, 0, time.UTC),
+			AvatarURL:   "https://avatars.githubusercontent.com/u/583231",
+		}, "following-only"),
+		NewRecord(github.User{Login: "hubot", Type: "User", SiteAdmin: true}, "followers-only"),
+		NewRecord(github.User{Login: "github", Type: "Organization"}, "mutual"),
+	}
+}
+
+func TestWrite_Golden(t *testing.T) {
+	files := map[string]string{
+		"text":     "list.txt",
+		"json":     "list.json",
+		"csv":      "list.csv",
+		"tsv":      "list.tsv",
+		"yaml":     "list.yaml",
+		"markdown": "list.md",
+	}
+
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, testRecords()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			golden := filepath.Join("testdata", files[format])
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if buf.String() != string(expected) {
+				t.Errorf("output does not match %s:\n--- got ---\n%s\n--- expected ---\n%s", golden, buf.String(), expected)
+			}
+		})
+	}
+}
+
+func TestWrite_Empty(t *testing.T) {
+	tests := map[string]string{
+		"text": "",
+		"json": "[]\n",
+		"yaml": "[]\n",
+		"csv":  "login,category,name,type,site_admin,company,location,bio,followers,following,public_repos,created_at,avatar_url\n",
+	}
+	for format, expected := range tests {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != expected {
+				t.Errorf("expected %q, got %q", expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "xml", testRecords()); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,154 @@
+// Package plan records the follow and unfollow changes that would bring the
+// authenticated user's relationships in sync, so they can be reviewed before
+// being applied.
+//
+// A plan stores the following and followers it was computed from. Applying it
+// against a different live state is refused, because the changes might no
+// longer be what the user reviewed.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/logins"
+)
+
+// Version is the plan file format version written by Save.
+const Version = 1
+
+// Plan is the set of changes computed for a user, with the state they were computed from.
+type Plan struct {
+	Version   int       `json:"version"`
+	User      string    `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	// Following and Followers are the sorted relationships the plan was computed from.
+	Following []string `json:"following"`
+	Followers []string `json:"followers"`
+	// Follow and Unfollow are the sorted changes to apply.
+	Follow   []string `json:"follow"`
+	Unfollow []string `json:"unfollow"`
+}
+
+// New computes a plan that follows back followers (if follow is set) and
+// unfollows users who don't follow back (if unfollow is set).
+func New(user string, following, followers []string, follow, unfollow bool) *Plan {
+	onlyFollowing, onlyFollowers, _ := github.GetMutualFollowsData(user, following, followers)
+	p := &Plan{
+		Version:   Version,
+		User:      user,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Following: logins.Sorted(following),
+		Followers: logins.Sorted(followers),
+		Follow:    []string{},
+		Unfollow:  []string{},
+	}
+	if follow {
+		p.Follow = logins.Sorted(onlyFollowers)
+	}
+	if unfollow {
+		p.Unfollow = logins.Sorted(onlyFollowing)
+	}
+	return p
+}
+
+// Empty reports whether the plan has no changes.
+func (p *Plan) Empty() bool {
+	return len(p.Follow) == 0 && len(p.Unfollow) == 0
+}
+
+// Save writes the plan to path as JSON.
+func (p *Plan) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// Load reads a plan written by Save.
+func Load(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	var p Plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+	if p.Version != Version {
+		return nil, fmt.Errorf("unsupported plan version %d in %s", p.Version, path)
+	}
+	if p.User == "" {
+		return nil, fmt.Errorf("plan %s has no user", path)
+	}
+	return &p, nil
+}
+
+// DriftError describes how the live relationships differ from those a plan was computed from.
+type DriftError struct {
+	User             string   // set when the plan was made for another user
+	FollowingAdded   []string // followed since the plan was made
+	FollowingRemoved []string // no longer followed
+	FollowersAdded   []string // new followers
+	FollowersRemoved []string // lost followers
+}
+
+func (e *DriftError) Error() string {
+	if e.User != "" {
+		return fmt.Sprintf("plan was made for %s", e.User)
+	}
+	var parts []string
+	add := func(label string, logins []string) {
+		if len(logins) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", label, strings.Join(logins, ", ")))
+		}
+	}
+	add("now following", e.FollowingAdded)
+	add("no longer following", e.FollowingRemoved)
+	add("new followers", e.FollowersAdded)
+	add("lost followers", e.FollowersRemoved)
+	return "relationships changed since the plan was made (" + strings.Join(parts, "; ") + ")"
+}
+
+// Check returns a *DriftError if the live relationships of user differ from
+// those the plan was computed from, and nil otherwise.
+func (p *Plan) Check(user string, following, followers []string) error {
+	if user != p.User {
+		return &DriftError{User: p.User}
+	}
+	d := &DriftError{}
+	d.FollowingAdded, d.FollowingRemoved = logins.Difference(p.Following, following)
+	d.FollowersAdded, d.FollowersRemoved = logins.Difference(p.Followers, followers)
+	if len(d.FollowingAdded)+len(d.FollowingRemoved)+len(d.FollowersAdded)+len(d.FollowersRemoved) > 0 {
+		return d
+	}
+	return nil
+}
+
+// WriteDiff prints the changes of the plan, one per line, followed by a summary.
+func (p *Plan) WriteDiff(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Plan for %s, created %s\n\n", p.User, p.CreatedAt.Format(time.RFC3339))
+	for _, login := range p.Follow {
+		fmt.Fprintf(&b, "+ follow   %s\n", login)
+	}
+	for _, login := range p.Unfollow {
+		fmt.Fprintf(&b, "- unfollow %s\n", login)
+	}
+	if p.Empty() {
+		b.WriteString("No changes.\n")
+	} else {
+		fmt.Fprintf(&b, "\n%d to follow, %d to unfollow.\n", len(p.Follow), len(p.Unfollow))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
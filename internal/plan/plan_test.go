@@ -0,0 +1,124 @@
+package plan
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	p := New("me", []string{"carol", "alice", "bob"}, []string{"bob", "erin", "dave"}, true, true)
+
+	if !reflect.DeepEqual(p.Follow, []string{"dave", "erin"}) {
+		t.Errorf("expected follow [dave erin], got %v", p.Follow)
+	}
+	if !reflect.DeepEqual(p.Unfollow, []string{"alice", "carol"}) {
+		t.Errorf("expected unfollow [alice carol], got %v", p.Unfollow)
+	}
+	if !reflect.DeepEqual(p.Following, []string{"alice", "bob", "carol"}) {
+		t.Errorf("expected sorted following, got %v", p.Following)
+	}
+
+	followOnly := New("me", []string{"alice"}, []string{"dave"}, true, false)
+	if len(followOnly.Unfollow) != 0 || len(followOnly.Follow) != 1 {
+		t.Errorf("expected only follows, got %+v", followOnly)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	p := New("me", []string{"alice"}, []string{"bob"}, true, true)
+
+	if err := p.Save(path); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, p) {
+		t.Errorf("expected %+v, got %+v", p, loaded)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{"Not JSON", "plan", "failed to parse plan"},
+		{"Unknown version", `{"version": 9, "user": "me"}`, "unsupported plan version 9"},
+		{"No user", `{"version": 1}`, "has no user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "plan.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := New("me", []string{"alice", "bob"}, []string{"bob", "dave"}, true, true)
+
+	if err := p.Check("me", []string{"bob", "alice"}, []string{"dave", "bob"}); err != nil {
+		t.Errorf("expected no drift for the same state in another order, got %v", err)
+	}
+
+	err := p.Check("me", []string{"bob", "carol"}, []string{"bob", "dave", "erin"})
+	var drift *DriftError
+	if !errors.As(err, &drift) {
+		t.Fatalf("expected a DriftError, got %v", err)
+	}
+	if !reflect.DeepEqual(drift.FollowingAdded, []string{"carol"}) || !reflect.DeepEqual(drift.FollowingRemoved, []string{"alice"}) {
+		t.Errorf("unexpected following drift %+v", drift)
+	}
+	if !reflect.DeepEqual(drift.FollowersAdded, []string{"erin"}) || drift.FollowersRemoved != nil {
+		t.Errorf("unexpected followers drift %+v", drift)
+	}
+	expected := "relationships changed since the plan was made (now following: carol; no longer following: alice; new followers: erin)"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+
+	if err := p.Check("someone-else", p.Following, p.Followers); !errors.As(err, &drift) || drift.User != "me" {
+		t.Errorf("expected a drift error for another user, got %v", err)
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	p := New("me", []string{"alice", "bob"}, []string{"bob", "dave"}, true, true)
+	p.CreatedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var b strings.Builder
+	if err := p.WriteDiff(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Plan for me, created 2024-05-01T12:00:00Z\n\n" +
+		"+ follow   dave\n" +
+		"- unfollow alice\n" +
+		"\n1 to follow, 1 to unfollow.\n"
+	if b.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+
+	b.Reset()
+	empty := New("me", []string{"bob"}, []string{"bob"}, true, true)
+	empty.WriteDiff(&b)
+	if !strings.HasSuffix(b.String(), "No changes.\n") {
+		t.Errorf("expected no changes, got %q", b.String())
+	}
+}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/history"
+)
+
+// edge is a relationship of an account with a login, observed from since until
+// until (zero while it lasts).
+type edge struct {
+	account string
+	kind    string
+	login   string
+	since   time.Time
+	until   time.Time
+}
+
+// open reports whether the edge still exists.
+func (e edge) open() bool {
+	return e.until.IsZero()
+}
+
+// existsAt reports whether the edge existed at t.
+func (e edge) existsAt(t time.Time) bool {
+	return !e.since.After(t) && (e.open() || e.until.After(t))
+}
+
+// Memory is a Store kept in memory, for tests. It is safe for concurrent use.
+type Memory struct {
+	mu        sync.Mutex
+	users     map[string]github.User
+	edges     []edge
+	snapshots map[string][]time.Time // account -> snapshot times, oldest first
+	last      string                 // account of the most recent snapshot
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{users: make(map[string]github.User), snapshots: make(map[string][]time.Time)}
+}
+
+func (m *Memory) Append(s history.Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	times := m.snapshots[s.Account]
+	if len(times) > 0 {
+		latest := times[len(times)-1]
+		if s.Time.Before(latest) {
+			return ErrOutOfOrder
+		}
+		if m.snapshotAt(s.Account, latest).SameLists(s) {
+			return nil
+		}
+	}
+
+	for kind, logins := range map[string][]string{kindFollowing: s.Following, kindFollower: s.Followers} {
+		current := make(map[string]bool)
+		for _, e := range m.edges {
+			if e.account == s.Account && e.kind == kind && e.open() {
+				current[e.login] = true
+			}
+		}
+		added, removed := changes(current, logins)
+		for _, login := range added {
+			m.edges = append(m.edges, edge{account: s.Account, kind: kind, login: login, since: s.Time})
+		}
+		ended := make(map[string]bool, len(removed))
+		for _, login := range removed {
+			ended[login] = true
+		}
+		for i, e := range m.edges {
+			if e.account == s.Account && e.kind == kind && e.open() && ended[e.login] {
+				m.edges[i].until = s.Time
+			}
+		}
+	}
+	m.snapshots[s.Account] = append(times, s.Time)
+	m.last = s.Account
+	return nil
+}
+
+func (m *Memory) Latest(account string) (history.Snapshot, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	times := m.snapshots[account]
+	if len(times) == 0 {
+		return history.Snapshot{}, false, nil
+	}
+	return m.snapshotAt(account, times[len(times)-1]), true, nil
+}
+
+func (m *Memory) Baseline(account string, t time.Time) (history.Snapshot, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	times := m.snapshots[account]
+	if len(times) == 0 {
+		return history.Snapshot{}, false, nil
+	}
+	baseline := times[0]
+	for _, st := range times {
+		if st.After(t) {
+			break
+		}
+		baseline = st
+	}
+	return m.snapshotAt(account, baseline), true, nil
+}
+
+// snapshotAt rebuilds the snapshot of account taken at t from the edges.
+func (m *Memory) snapshotAt(account string, t time.Time) history.Snapshot {
+	var following, followers []string
+	for _, e := range m.edges {
+		if e.account != account || !e.existsAt(t) {
+			continue
+		}
+		if e.kind == kindFollowing {
+			following = append(following, e.login)
+		} else {
+			followers = append(followers, e.login)
+		}
+	}
+	s := history.NewSnapshot(account, following, followers)
+	s.Time = t
+	return s
+}
+
+func (m *Memory) SaveUsers(users []github.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range users {
+		m.users[u.Login] = u
+	}
+	return nil
+}
+
+func (m *Memory) Users(logins []string) (map[string]github.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	users := make(map[string]github.User, len(logins))
+	for _, login := range logins {
+		if u, ok := m.users[login]; ok {
+			users[login] = u
+		}
+	}
+	return users, nil
+}
+
+func (m *Memory) LastAccount() (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last, m.last != "", nil
+}
+
+func (m *Memory) FollowersSince(account string, t time.Time) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var logins []string
+	for _, e := range m.edges {
+		if e.account == account && e.kind == kindFollower && e.open() && !e.since.After(t) {
+			logins = append(logins, e.login)
+		}
+	}
+	sort.Strings(logins)
+	return logins, nil
+}
+
+func (m *Memory) FollowedBackSince(account string, t time.Time) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	followerSince := make(map[string]time.Time)
+	for _, e := range m.edges {
+		if e.account == account && e.kind == kindFollower && e.open() {
+			followerSince[e.login] = e.since
+		}
+	}
+	var logins []string
+	for _, e := range m.edges {
+		if e.account != account || e.kind != kindFollowing || !e.open() || !e.since.After(t) {
+			continue
+		}
+		if since, ok := followerSince[e.login]; ok && since.Before(e.since) {
+			logins = append(logins, e.login)
+		}
+	}
+	sort.Strings(logins)
+	return logins, nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
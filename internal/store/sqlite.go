@@ -0,0 +1,290 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/history"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+// schema creates the tables. Times are Unix nanoseconds in UTC; until is NULL while an edge lasts.
+const schema = `
+CREATE TABLE IF NOT EXISTS users (
+	login      TEXT PRIMARY KEY,
+	data       TEXT NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS edges (
+	account TEXT NOT NULL,
+	kind    TEXT NOT NULL,
+	login   TEXT NOT NULL,
+	since   INTEGER NOT NULL,
+	until   INTEGER
+);
+CREATE INDEX IF NOT EXISTS edges_by_account ON edges (account, kind, until);
+CREATE TABLE IF NOT EXISTS snapshots (
+	account TEXT NOT NULL,
+	time    INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS snapshots_by_account ON snapshots (account, time);
+`
+
+// SQLite is a Store in a SQLite database file. It is safe for concurrent use.
+type SQLite struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it and its directory if needed.
+func OpenSQLite(path string) (*SQLite, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	return &SQLite{db: db}, nil
+}
+
+func (s *SQLite) Append(snap history.Snapshot) (err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var latest sql.NullInt64
+	if err := tx.QueryRow(`SELECT MAX(time) FROM snapshots WHERE account = ?`, snap.Account).Scan(&latest); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	at := snap.Time.UnixNano()
+	if latest.Valid && at < latest.Int64 {
+		return ErrOutOfOrder
+	}
+
+	changed := !latest.Valid
+	for kind, logins := range map[string][]string{kindFollowing: snap.Following, kindFollower: snap.Followers} {
+		current, err := openEdges(tx, snap.Account, kind)
+		if err != nil {
+			return fmt.Errorf("failed to save snapshot: %w", err)
+		}
+		added, removed := changes(current, logins)
+		for _, login := range added {
+			if _, err := tx.Exec(`INSERT INTO edges (account, kind, login, since) VALUES (?, ?, ?, ?)`,
+				snap.Account, kind, login, at); err != nil {
+				return fmt.Errorf("failed to save snapshot: %w", err)
+			}
+		}
+		for _, login := range removed {
+			if _, err := tx.Exec(`UPDATE edges SET until = ? WHERE account = ? AND kind = ? AND login = ? AND until IS NULL`,
+				at, snap.Account, kind, login); err != nil {
+				return fmt.Errorf("failed to save snapshot: %w", err)
+			}
+		}
+		changed = changed || len(added) > 0 || len(removed) > 0
+	}
+	if !changed {
+		return tx.Rollback() // same lists as the latest snapshot
+	}
+	if _, err := tx.Exec(`INSERT INTO snapshots (account, time) VALUES (?, ?)`, snap.Account, at); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return nil
+}
+
+// openEdges returns the logins of the edges of account of a kind that still exist.
+func openEdges(tx *sql.Tx, account, kind string) (map[string]bool, error) {
+	rows, err := tx.Query(`SELECT login FROM edges WHERE account = ? AND kind = ? AND until IS NULL`, account, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	logins := make(map[string]bool)
+	for rows.Next() {
+		var login string
+		if err := rows.Scan(&login); err != nil {
+			return nil, err
+		}
+		logins[login] = true
+	}
+	return logins, rows.Err()
+}
+
+func (s *SQLite) Latest(account string) (history.Snapshot, bool, error) {
+	var at sql.NullInt64
+	if err := s.db.QueryRow(`SELECT MAX(time) FROM snapshots WHERE account = ?`, account).Scan(&at); err != nil {
+		return history.Snapshot{}, false, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+	if !at.Valid {
+		return history.Snapshot{}, false, nil
+	}
+	return s.snapshotAt(account, at.Int64)
+}
+
+func (s *SQLite) Baseline(account string, t time.Time) (history.Snapshot, bool, error) {
+	var at sql.NullInt64
+	err := s.db.QueryRow(`SELECT COALESCE(
+		(SELECT MAX(time) FROM snapshots WHERE account = ? AND time <= ?),
+		(SELECT MIN(time) FROM snapshots WHERE account = ?))`,
+		account, t.UnixNano(), account).Scan(&at)
+	if err != nil {
+		return history.Snapshot{}, false, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+	if !at.Valid {
+		return history.Snapshot{}, false, nil
+	}
+	return s.snapshotAt(account, at.Int64)
+}
+
+// snapshotAt rebuilds the snapshot of account taken at the given time from the edges.
+func (s *SQLite) snapshotAt(account string, at int64) (history.Snapshot, bool, error) {
+	rows, err := s.db.Query(`SELECT kind, login FROM edges
+		WHERE account = ? AND since <= ? AND (until IS NULL OR until > ?)`, account, at, at)
+	if err != nil {
+		return history.Snapshot{}, false, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+	defer rows.Close()
+	var following, followers []string
+	for rows.Next() {
+		var kind, login string
+		if err := rows.Scan(&kind, &login); err != nil {
+			return history.Snapshot{}, false, fmt.Errorf("failed to read snapshots: %w", err)
+		}
+		if kind == kindFollowing {
+			following = append(following, login)
+		} else {
+			followers = append(followers, login)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return history.Snapshot{}, false, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+	snap := history.NewSnapshot(account, following, followers)
+	snap.Time = time.Unix(0, at).UTC()
+	return snap, true, nil
+}
+
+func (s *SQLite) SaveUsers(users []github.User) (err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save users: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	now := time.Now().UnixNano()
+	for _, u := range users {
+		data, err := json.Marshal(u)
+		if err != nil {
+			return fmt.Errorf("failed to encode user %s: %w", u.Login, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO users (login, data, updated_at) VALUES (?, ?, ?)
+			ON CONFLICT (login) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+			u.Login, string(data), now); err != nil {
+			return fmt.Errorf("failed to save users: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save users: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLite) Users(logins []string) (map[string]github.User, error) {
+	users := make(map[string]github.User, len(logins))
+	stmt, err := s.db.Prepare(`SELECT data FROM users WHERE login = ?`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users: %w", err)
+	}
+	defer stmt.Close()
+	for _, login := range logins {
+		var data string
+		err := stmt.QueryRow(login).Scan(&data)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read users: %w", err)
+		}
+		var u github.User
+		if err := json.Unmarshal([]byte(data), &u); err != nil {
+			return nil, fmt.Errorf("failed to decode user %s: %w", login, err)
+		}
+		users[login] = u
+	}
+	return users, nil
+}
+
+func (s *SQLite) LastAccount() (string, bool, error) {
+	var account string
+	err := s.db.QueryRow(`SELECT account FROM snapshots ORDER BY time DESC LIMIT 1`).Scan(&account)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+	return account, true, nil
+}
+
+func (s *SQLite) FollowersSince(account string, t time.Time) ([]string, error) {
+	return s.logins(`SELECT login FROM edges
+		WHERE account = ? AND kind = ? AND until IS NULL AND since <= ?`,
+		account, kindFollower, t.UnixNano())
+}
+
+func (s *SQLite) FollowedBackSince(account string, t time.Time) ([]string, error) {
+	return s.logins(`SELECT f.login FROM edges f JOIN edges r
+		ON r.account = f.account AND r.login = f.login AND r.kind = ? AND r.until IS NULL
+		WHERE f.account = ? AND f.kind = ? AND f.until IS NULL AND f.since > ? AND r.since < f.since`,
+		kindFollower, account, kindFollowing, t.UnixNano())
+}
+
+// logins runs a query returning logins, and sorts them.
+func (s *SQLite) logins(query string, args ...any) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query edges: %w", err)
+	}
+	defer rows.Close()
+	var logins []string
+	for rows.Next() {
+		var login string
+		if err := rows.Scan(&login); err != nil {
+			return nil, fmt.Errorf("failed to query edges: %w", err)
+		}
+		logins = append(logins, login)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query edges: %w", err)
+	}
+	sort.Strings(logins)
+	return logins, nil
+}
+
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,89 @@
+// Package store is a durable local copy of users, follow edges and relationship
+// snapshots. It lets the TUI start from cached data and answers questions about
+// how long relationships have lasted.
+//
+// Edges record when a relationship was first and last observed, so their times
+// are those of the snapshots, not of the follows themselves: a follower seen in
+// the first snapshot is considered to follow since that snapshot.
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/history"
+	"gh-mutual-follow/internal/xdg"
+)
+
+// Store holds users, the follow edges of accounts over time and their snapshots.
+type Store interface {
+	history.Store
+
+	// SaveUsers stores the profiles of users, replacing older ones.
+	SaveUsers(users []github.User) error
+	// Users returns the stored profiles of logins. Unknown logins are left out.
+	Users(logins []string) (map[string]github.User, error)
+	// LastAccount returns the account of the most recent snapshot, if any.
+	LastAccount() (string, bool, error)
+	// FollowersSince returns the current followers of account that have followed it
+	// since t or earlier, sorted.
+	FollowersSince(account string, t time.Time) ([]string, error)
+	// FollowedBackSince returns the users account started following after t who
+	// were already following it, sorted.
+	FollowedBackSince(account string, t time.Time) ([]string, error)
+	// Close releases the store.
+	Close() error
+}
+
+// Edge kinds: the account follows the login, or the login follows the account.
+const (
+	kindFollowing = "following"
+	kindFollower  = "follower"
+)
+
+// ErrOutOfOrder is returned when a snapshot is older than the latest one of its account.
+var ErrOutOfOrder = errors.New("snapshot is older than the latest one")
+
+// DefaultPath returns the database location under the XDG state directory:
+// $XDG_STATE_HOME/gh-mutual-follow/store.db, or ~/.local/state/... when unset.
+func DefaultPath() (string, error) {
+	return xdg.StatePath("store.db")
+}
+
+// ImportLog copies the snapshots of log into an empty store, so the history kept
+// before the store existed is not lost. A store that has snapshots is left alone.
+// It returns the number of snapshots read from the log.
+func ImportLog(s Store, log *history.Log) (int, error) {
+	if _, ok, err := s.LastAccount(); err != nil || ok {
+		return 0, err
+	}
+	snapshots, err := log.All()
+	if err != nil {
+		return 0, err
+	}
+	for _, snap := range snapshots {
+		if err := s.Append(snap); err != nil {
+			return 0, fmt.Errorf("failed to import snapshot log: %w", err)
+		}
+	}
+	return len(snapshots), nil
+}
+
+// changes returns the logins to add to and remove from current to get to next.
+func changes(current map[string]bool, next []string) (added, removed []string) {
+	inNext := make(map[string]bool, len(next))
+	for _, login := range next {
+		inNext[login] = true
+		if !current[login] {
+			added = append(added, login)
+		}
+	}
+	for login := range current {
+		if !inNext[login] {
+			removed = append(removed, login)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,219 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/history"
+)
+
+// implementations returns a fresh store of each kind, closed when the test ends.
+func implementations(t *testing.T) map[string]Store {
+	t.Helper()
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "state", "store.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return map[string]Store{"memory": NewMemory(), "sqlite": db}
+}
+
+func day(n int) time.Time {
+	return time.Date(2024, 5, n, 12, 0, 0, 0, time.UTC)
+}
+
+func snapshotAt(at time.Time, account string, following, followers []string) history.Snapshot {
+	s := history.NewSnapshot(account, following, followers)
+	s.Time = at
+	return s
+}
+
+func appendAll(t *testing.T, s Store, snapshots ...history.Snapshot) {
+	t.Helper()
+	for _, snap := range snapshots {
+		if err := s.Append(snap); err != nil {
+			t.Fatalf("failed to append snapshot of %s: %v", snap.Time, err)
+		}
+	}
+}
+
+func TestStore_Snapshots(t *testing.T) {
+	for name, s := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, ok, err := s.Latest("me"); err != nil || ok {
+				t.Fatalf("expected no snapshot in an empty store, got ok=%v err=%v", ok, err)
+			}
+			if _, ok, err := s.LastAccount(); err != nil || ok {
+				t.Fatalf("expected no account in an empty store, got ok=%v err=%v", ok, err)
+			}
+
+			appendAll(t, s,
+				snapshotAt(day(1), "me", []string{"alice"}, nil),
+				snapshotAt(day(2), "me", []string{"alice"}, nil), // unchanged: not stored
+				snapshotAt(day(5), "me", []string{"alice", "bob"}, []string{"carol"}),
+				snapshotAt(day(9), "me", []string{"bob"}, []string{"carol"}),
+				snapshotAt(day(10), "other", []string{"zed"}, nil),
+			)
+
+			tests := []struct {
+				since     time.Time
+				want      time.Time
+				following []string
+			}{
+				{day(6), day(5), []string{"alice", "bob"}},
+				{day(2), day(1), []string{"alice"}},
+				{day(30), day(9), []string{"bob"}},
+				{day(0), day(1), []string{"alice"}}, // nothing that old: the oldest one
+			}
+			for _, tt := range tests {
+				snap, ok, err := s.Baseline("me", tt.since)
+				if err != nil || !ok {
+					t.Fatalf("Baseline(%s): ok=%v err=%v", tt.since, ok, err)
+				}
+				if !snap.Time.Equal(tt.want) || !reflect.DeepEqual(snap.Following, tt.following) {
+					t.Errorf("Baseline(%s) = %v following %v, want %v following %v", tt.since, snap.Time, snap.Following, tt.want, tt.following)
+				}
+			}
+
+			latest, ok, err := s.Latest("me")
+			if err != nil || !ok {
+				t.Fatalf("Latest: ok=%v err=%v", ok, err)
+			}
+			want := snapshotAt(day(9), "me", []string{"bob"}, []string{"carol"})
+			if !latest.Time.Equal(want.Time) || !latest.SameLists(want) {
+				t.Errorf("unexpected latest snapshot %+v", latest)
+			}
+
+			if account, ok, err := s.LastAccount(); err != nil || !ok || account != "other" {
+				t.Errorf("LastAccount() = %q, %v, %v; want other", account, ok, err)
+			}
+
+			if err := s.Append(snapshotAt(day(3), "me", nil, nil)); !errors.Is(err, ErrOutOfOrder) {
+				t.Errorf("expected ErrOutOfOrder for an old snapshot, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStore_Relationships(t *testing.T) {
+	for name, s := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			appendAll(t, s,
+				snapshotAt(day(1), "me", []string{"alice"}, []string{"alice", "bob", "carol"}),
+				snapshotAt(day(4), "me", []string{"alice", "bob", "dave"}, []string{"alice", "bob", "carol", "erin"}),
+				snapshotAt(day(8), "me", []string{"alice", "bob", "dave", "erin"}, []string{"alice", "bob", "erin"}),
+			)
+
+			followers, err := s.FollowersSince("me", day(3))
+			if err != nil {
+				t.Fatalf("FollowersSince: %v", err)
+			}
+			if want := []string{"alice", "bob"}; !reflect.DeepEqual(followers, want) {
+				t.Errorf("FollowersSince(day 3) = %v, want %v", followers, want)
+			}
+
+			back, err := s.FollowedBackSince("me", day(2))
+			if err != nil {
+				t.Fatalf("FollowedBackSince: %v", err)
+			}
+			// dave never followed; erin and bob followed before being followed.
+			if want := []string{"bob", "erin"}; !reflect.DeepEqual(back, want) {
+				t.Errorf("FollowedBackSince(day 2) = %v, want %v", back, want)
+			}
+			back, err = s.FollowedBackSince("me", day(5))
+			if err != nil {
+				t.Fatalf("FollowedBackSince: %v", err)
+			}
+			if want := []string{"erin"}; !reflect.DeepEqual(back, want) {
+				t.Errorf("FollowedBackSince(day 5) = %v, want %v", back, want)
+			}
+		})
+	}
+}
+
+func TestStore_Users(t *testing.T) {
+	for name, s := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			created := time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC)
+			if err := s.SaveUsers([]github.User{
+				{Login: "alice", Name: "Alice", Followers: 10, CreatedAt: created},
+				{Login: "bob"},
+			}); err != nil {
+				t.Fatalf("SaveUsers: %v", err)
+			}
+			if err := s.SaveUsers([]github.User{{Login: "bob", Name: "Bob"}}); err != nil {
+				t.Fatalf("SaveUsers: %v", err)
+			}
+
+			users, err := s.Users([]string{"alice", "bob", "nobody"})
+			if err != nil {
+				t.Fatalf("Users: %v", err)
+			}
+			if len(users) != 2 {
+				t.Fatalf("expected 2 users, got %v", users)
+			}
+			if u := users["alice"]; u.Name != "Alice" || u.Followers != 10 || !u.CreatedAt.Equal(created) {
+				t.Errorf("unexpected alice %+v", u)
+			}
+			if u := users["bob"]; u.Name != "Bob" {
+				t.Errorf("expected bob to be replaced, got %+v", u)
+			}
+		})
+	}
+}
+
+func TestSQLite_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	db, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	appendAll(t, db, snapshotAt(day(1), "me", []string{"alice"}, []string{"bob"}))
+	if err := db.Close(); err != nil {
+		t.Fatalf("failed to close store: %v", err)
+	}
+
+	db, err = OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer db.Close()
+	latest, ok, err := db.Latest("me")
+	if err != nil || !ok {
+		t.Fatalf("Latest: ok=%v err=%v", ok, err)
+	}
+	if !reflect.DeepEqual(latest.Following, []string{"alice"}) || !reflect.DeepEqual(latest.Followers, []string{"bob"}) {
+		t.Errorf("unexpected snapshot after reopening %+v", latest)
+	}
+}
+
+func TestImportLog(t *testing.T) {
+	log := history.NewLog(filepath.Join(t.TempDir(), "snapshots.jsonl"))
+	for _, snap := range []history.Snapshot{
+		snapshotAt(day(1), "me", []string{"alice"}, nil),
+		snapshotAt(day(3), "me", []string{"alice", "bob"}, []string{"bob"}),
+	} {
+		if err := log.Append(snap); err != nil {
+			t.Fatalf("failed to append: %v", err)
+		}
+	}
+
+	s := NewMemory()
+	n, err := ImportLog(s, log)
+	if err != nil || n != 2 {
+		t.Fatalf("ImportLog() = %d, %v; want 2 snapshots", n, err)
+	}
+	baseline, ok, err := s.Baseline("me", day(2))
+	if err != nil || !ok || !reflect.DeepEqual(baseline.Following, []string{"alice"}) {
+		t.Errorf("unexpected baseline %+v (ok=%v err=%v)", baseline, ok, err)
+	}
+
+	// A store with snapshots is not imported into again.
+	if n, err := ImportLog(s, log); err != nil || n != 0 {
+		t.Errorf("second ImportLog() = %d, %v; want 0", n, err)
+	}
+}
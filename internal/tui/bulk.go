@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gh-mutual-follow/internal/bulk"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bulkProgressMsg is streamed to the model after each user is processed.
+type bulkProgressMsg struct {
+	progress bulk.Progress
+}
+
+// bulkDoneMsg is sent once every user of a bulk action has been processed.
+type bulkDoneMsg struct {
+	report bulk.Report
+}
+
+// runBulk executes a bulk action and streams its progress and final report to ch,
+// which is closed at the end.
+func runBulk(ctx context.Context, executor *bulk.Executor, action bulk.Action, logins []string, ch chan<- tea.Msg) {
+	defer close(ch)
+	report := executor.Run(ctx, action, logins, func(p bulk.Progress) {
+		ch <- bulkProgressMsg{progress: p}
+	})
+	ch <- bulkDoneMsg{report: report}
+}
+
+// waitForBulkMsg reads the next message of a running bulk action.
+func waitForBulkMsg(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// renderBulkProgress renders the progress bar with the count, ETA and current user.
+func (m tuiModel) renderBulkProgress() string {
+	p := m.bulkProgress
+	info := fmt.Sprintf("Bulk %s %d/%d", m.bulkAction, p.Done, p.Total)
+	if p.Done > 0 && p.Done < p.Total {
+		info += fmt.Sprintf("   ETA %s", p.ETA().Round(time.Second))
+	}
+	if p.Result.Login != "" {
+		info += fmt.Sprintf("   %s %s", p.Result.Login, p.Result.Status)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		" "+m.progress.ViewAs(p.Percent()),
+		m.styles.StatusMessage.Render(info),
+	)
+}
+
+// renderBulkSummary renders the result of a bulk action, listing every failure.
+func renderBulkSummary(styles *TUIStyles, r bulk.Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", styles.DetailTitle.Render(fmt.Sprintf("Bulk %s finished", r.Action)))
+	fmt.Fprintf(&b, "%d succeeded, %d failed, %d skipped\n",
+		r.Count(bulk.Succeeded), r.Count(bulk.Failed), r.Count(bulk.Skipped))
+
+	if r.Count(bulk.Failed) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", styles.ErrorStyle.Render("Failures:"))
+		for _, res := range r.Results {
+			if res.Status == bulk.Failed {
+				fmt.Fprintf(&b, "  %s: %v\n", res.Login, res.Err)
+			}
+		}
+	}
+	if r.Count(bulk.Skipped) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", styles.DetailLabel.Render("Skipped:"))
+		for _, res := range r.Results {
+			if res.Status == bulk.Skipped {
+				fmt.Fprintf(&b, "  %s: %v\n", res.Login, res.Err)
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+
+	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/store"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loadCachedCmd loads the relationships saved by the last run, so the panes can be
+// shown before the fresh lists arrive. It returns nothing when there is no cache.
+func loadCachedCmd(s store.Store) tea.Cmd {
+	return func() tea.Msg {
+		account, ok, err := s.LastAccount()
+		if err != nil || !ok {
+			return nil
+		}
+		snap, ok, err := s.Latest(account)
+		if err != nil || !ok {
+			return nil
+		}
+		users, err := s.Users(append(append([]string(nil), snap.Following...), snap.Followers...))
+		if err != nil {
+			return nil
+		}
+		profiles := make(map[string]github.User, len(snap.Following)+len(snap.Followers))
+		for _, logins := range [][]string{snap.Following, snap.Followers} {
+			for _, login := range logins {
+				u, ok := users[login]
+				if !ok {
+					u = github.User{Login: login}
+				}
+				profiles[login] = u
+			}
+		}
+
+		onlyFollowing, onlyFollowers, mutual := github.GetMutualFollowsData(account, snap.Following, snap.Followers)
+		return dataLoadedMsg{
+			username:      account,
+			onlyFollowing: sortedItems(onlyFollowing, profiles),
+			onlyFollowers: sortedItems(onlyFollowers, profiles),
+			mutual:        sortedItems(mutual, profiles),
+			cached:        true,
+		}
+	}
+}
+
+// saveUsersCmd stores the profiles of a data load for the next start.
+func saveUsersCmd(s store.Store, msg dataLoadedMsg) tea.Cmd {
+	var users []github.User
+	for _, items := range [][]list.Item{msg.onlyFollowing, msg.onlyFollowers, msg.mutual} {
+		for _, li := range items {
+			users = append(users, li.(item).user)
+		}
+	}
+	return func() tea.Msg {
+		if err := s.SaveUsers(users); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to save users: %v", err))
+		}
+		return nil
+	}
+}
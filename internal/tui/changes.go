@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gh-mutual-follow/internal/history"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// changesWindows are the periods the Changes view cycles through with tab.
+var changesWindows = []struct {
+	label    string
+	duration time.Duration
+}{
+	{"24 hours", 24 * time.Hour},
+	{"7 days", 7 * 24 * time.Hour},
+	{"30 days", 30 * 24 * time.Hour},
+}
+
+// defaultChangesWindow is the period shown when the Changes view opens: 7 days.
+const defaultChangesWindow = 1
+
+// changesPreview is the number of logins listed per kind of change.
+const changesPreview = 10
+
+// changesView shows who followed, unfollowed, was followed or was unfollowed
+// during a period, according to the snapshot history.
+type changesView struct {
+	window  int // index in changesWindows
+	diff    history.Diff
+	found   bool // a snapshot to compare with exists
+	partial bool // the history does not go back the whole period
+}
+
+// changesMsg carries the changes computed for a period.
+type changesMsg changesView
+
+// changesCmd compares current with the snapshot taken at the start of the period.
+func changesCmd(log history.Store, current history.Snapshot, window int) tea.Cmd {
+	return func() tea.Msg {
+		since := current.Time.Add(-changesWindows[window].duration)
+		baseline, ok, err := log.Baseline(current.Account, since)
+		if err != nil {
+			return statusMsg(fmt.Sprintf("Failed to read the snapshot history: %v", err))
+		}
+		msg := changesMsg{window: window, found: ok}
+		if ok {
+			msg.diff = history.Compare(baseline, current)
+			msg.partial = baseline.Time.After(since)
+		}
+		return msg
+	}
+}
+
+// saveSnapshotCmd records the loaded relationships in the history.
+func saveSnapshotCmd(log history.Store, s history.Snapshot) tea.Cmd {
+	return func() tea.Msg {
+		if err := log.Append(s); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to save snapshot: %v", err))
+		}
+		return nil
+	}
+}
+
+// currentSnapshot returns the relationships shown in the panes as a snapshot taken now.
+func (m tuiModel) currentSnapshot() history.Snapshot {
+	logins := func(panes ...int) []string {
+		var result []string
+		for _, i := range panes {
+			for _, li := range m.panes[i].items {
+				result = append(result, li.(item).user.Login)
+			}
+		}
+		return result
+	}
+	return history.NewSnapshot(m.username,
+		logins(followingPane, mutualPane),
+		logins(followersPane, mutualPane),
+	)
+}
+
+// snapshotOf returns the relationships of a data load as a snapshot taken now.
+func snapshotOf(msg dataLoadedMsg) history.Snapshot {
+	logins := func(lists ...[]list.Item) []string {
+		var result []string
+		for _, items := range lists {
+			for _, li := range items {
+				result = append(result, li.(item).user.Login)
+			}
+		}
+		return result
+	}
+	return history.NewSnapshot(msg.username,
+		logins(msg.onlyFollowing, msg.mutual),
+		logins(msg.onlyFollowers, msg.mutual),
+	)
+}
+
+// renderChanges renders the changes of a period, listing a few logins of each kind.
+func renderChanges(styles *TUIStyles, c changesView) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", styles.DetailTitle.Render("Changes in the last "+changesWindows[c.window].label))
+	if !c.found {
+		b.WriteString("No snapshot to compare with yet. Snapshots are saved on every load.")
+		return b.String()
+	}
+	if c.partial {
+		fmt.Fprintf(&b, "%s\n\n", styles.DetailLabel.Render("History only goes back to "+c.diff.From.Local().Format("2006-01-02 15:04")))
+	}
+	if c.diff.Empty() {
+		b.WriteString("No changes.")
+		return b.String()
+	}
+
+	section := func(title string, logins []string) {
+		if len(logins) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s\n", styles.DetailLabel.Render(fmt.Sprintf("%s (%d)", title, len(logins))))
+		shown := logins
+		if len(shown) > changesPreview {
+			shown = shown[:changesPreview]
+		}
+		for _, login := range shown {
+			fmt.Fprintf(&b, "  %s\n", login)
+		}
+		if more := len(logins) - len(shown); more > 0 {
+			fmt.Fprintf(&b, "  ... and %d more\n", more)
+		}
+		b.WriteString("\n")
+	}
+	section("New followers", c.diff.NewFollowers)
+	section("Lost followers", c.diff.LostFollowers)
+	section("Started following", c.diff.NewFollowing)
+	section("Stopped following", c.diff.EndedFollowing)
+	return strings.TrimSuffix(b.String(), "\n\n")
+}
@@ -1,10 +1,13 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"time"
 
+	"gh-mutual-follow/internal/audit"
+	"gh-mutual-follow/internal/bulk"
 	"gh-mutual-follow/internal/github"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -18,58 +21,91 @@ func clearStatusMsg() tea.Cmd {
 	})
 }
 
+// pauseTickCmd ticks once a second while a rate limit pause is shown.
+func pauseTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return pauseTickMsg{}
+	})
+}
+
 // loadDataCmd fetches all the necessary data from the GitHub client.
-func loadDataCmd(client github.Client) tea.Cmd {
+// The fetch is aborted when ctx is cancelled.
+func loadDataCmd(ctx context.Context, client github.Client) tea.Cmd {
 	return func() tea.Msg {
-		username, err := client.GetUser()
+		username, err := client.GetUser(ctx)
 		if err != nil {
 			return errorMsg{fmt.Errorf("failed to get user: %w", err)}
 		}
 
-		following, err := client.GetFollowing(username)
+		following, err := client.GetFollowingUsers(ctx, username)
 		if err != nil {
 			return errorMsg{fmt.Errorf("failed to get following: %w", err)}
 		}
 
-		followers, err := client.GetFollowers(username)
+		followers, err := client.GetFollowersUsers(ctx, username)
 		if err != nil {
 			return errorMsg{fmt.Errorf("failed to get followers: %w", err)}
 		}
 
-		onlyFollowingStr, onlyFollowersStr := github.GetMutualFollowsData(username, following, followers)
-
-		// Create []item slices for sorting
-		onlyFollowingItems := make([]item, len(onlyFollowingStr))
-		for i, u := range onlyFollowingStr {
-			onlyFollowingItems[i] = item(u)
-		}
-		sort.Slice(onlyFollowingItems, func(i, j int) bool {
-			return onlyFollowingItems[i].FilterValue() < onlyFollowingItems[j].FilterValue()
-		})
-
-		onlyFollowersItems := make([]item, len(onlyFollowersStr))
-		for i, u := range onlyFollowersStr {
-			onlyFollowersItems[i] = item(u)
+		profiles := make(map[string]github.User, len(following)+len(followers))
+		for _, u := range following {
+			profiles[u.Login] = u
 		}
-		sort.Slice(onlyFollowersItems, func(i, j int) bool {
-			return onlyFollowersItems[i].FilterValue() < onlyFollowersItems[j].FilterValue()
-		})
-
-		// Convert sorted []item to []list.Item for the message
-		finalFollowingItems := make([]list.Item, len(onlyFollowingItems))
-		for i, itm := range onlyFollowingItems {
-			finalFollowingItems[i] = itm
+		for _, u := range followers {
+			profiles[u.Login] = u
 		}
 
-		finalFollowersItems := make([]list.Item, len(onlyFollowersItems))
-		for i, itm := range onlyFollowersItems {
-			finalFollowersItems[i] = itm
-		}
+		onlyFollowing, onlyFollowers, mutual := github.GetMutualFollowsData(username, github.Logins(following), github.Logins(followers))
 
 		return dataLoadedMsg{
 			username:      username,
-			onlyFollowing: finalFollowingItems,
-			onlyFollowers: finalFollowersItems,
+			onlyFollowing: sortedItems(onlyFollowing, profiles),
+			onlyFollowers: sortedItems(onlyFollowers, profiles),
+			mutual:        sortedItems(mutual, profiles),
+		}
+	}
+}
+
+// sortedItems returns the list items of logins, sorted by login.
+func sortedItems(logins []string, profiles map[string]github.User) []list.Item {
+	sorted := append([]string(nil), logins...)
+	sort.Strings(sorted)
+	items := make([]list.Item, len(sorted))
+	for i, login := range sorted {
+		items[i] = item{user: profiles[login]}
+	}
+	return items
+}
+
+// undoMsg starts the bulk action that reverses an audited batch.
+type undoMsg struct {
+	batch  string
+	action bulk.Action
+	logins []string
+}
+
+// undoCmd finds the last batch of account that can be undone and the action that reverses it.
+func undoCmd(log *audit.Log, account string) tea.Cmd {
+	return func() tea.Msg {
+		batch, entries, err := log.LastBatch(account)
+		if err != nil {
+			return errorMsg{fmt.Errorf("failed to read the audit log: %w", err)}
+		}
+		if batch == "" {
+			return statusMsg("Nothing to undo")
+		}
+		follow, unfollow, err := audit.Revert(entries)
+		if err != nil {
+			return errorMsg{err}
+		}
+		switch {
+		case len(follow) > 0 && len(unfollow) > 0:
+			return statusMsg(fmt.Sprintf("Batch %s mixes follows and unfollows, revert its session from the command line", batch))
+		case len(follow) > 0:
+			return undoMsg{batch: batch, action: bulk.Follow, logins: follow}
+		case len(unfollow) > 0:
+			return undoMsg{batch: batch, action: bulk.Unfollow, logins: unfollow}
 		}
+		return statusMsg("Nothing to undo")
 	}
 }
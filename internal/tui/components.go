@@ -3,20 +3,82 @@ package tui
 import (
 	"fmt"
 	"io"
+	"strings"
+
+	"gh-mutual-follow/internal/config"
+	"gh-mutual-follow/internal/github"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
-// item represents an item in the list.
-type item string
+// item represents a user in the list.
+type item struct {
+	user         github.User
+	loginMatches []int // positions in the login matched by the search, for highlighting
+	nameMatches  []int // positions in the name matched by the search
+}
+
+// newItem creates an item that only knows the login of the user.
+func newItem(login string) item {
+	return item{user: github.User{Login: login}}
+}
 
 // FilterValue is required by the list.Model interface.
-func (i item) FilterValue() string { return string(i) }
+func (i item) FilterValue() string { return i.user.Login }
+
+// selection is the set of checked logins of a pane.
+type selection map[string]bool
+
+// toggle checks login if it is unchecked, and unchecks it otherwise.
+func (s selection) toggle(login string) {
+	if s[login] {
+		delete(s, login)
+	} else {
+		s[login] = true
+	}
+}
+
+// clear unchecks every login.
+func (s selection) clear() {
+	for login := range s {
+		delete(s, login)
+	}
+}
+
+// logins returns the checked logins among items, in list order.
+func (s selection) logins(items []list.Item) []string {
+	var logins []string
+	for _, li := range items {
+		if i, ok := li.(item); ok && s[i.user.Login] {
+			logins = append(logins, i.user.Login)
+		}
+	}
+	return logins
+}
+
+// prune unchecks the logins that are no longer in items.
+func (s selection) prune(items []list.Item) {
+	present := make(map[string]bool, len(items))
+	for _, li := range items {
+		if i, ok := li.(item); ok {
+			present[i.user.Login] = true
+		}
+	}
+	for login := range s {
+		if !present[login] {
+			delete(s, login)
+		}
+	}
+}
 
 // itemDelegate is responsible for rendering list items.
 type itemDelegate struct {
-	styles *TUIStyles
+	styles   *TUIStyles
+	selected selection      // checked items, shared with the model
+	states   itemStates     // progress of single actions, shared with the model
+	lists    *config.Config // allowlist and denylist, shared with the model
 }
 
 func (d itemDelegate) Height() int                               { return 1 }
@@ -28,11 +90,106 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, li list.Item)
 		return
 	}
 
-	str := i.FilterValue()
+	str := highlight(i.user.Login, i.loginMatches, d.styles.MatchStyle)
+	name := ""
+	if i.user.Name != "" {
+		name = " " + d.styles.NameStyle.Render(highlight(i.user.Name, i.nameMatches, d.styles.MatchStyle))
+	}
+
+	check := "[ ] "
+	if d.selected[i.user.Login] {
+		check = d.styles.CheckedStyle.Render("[x]") + " "
+	}
+
+	switch {
+	case d.lists.Allowed(i.user.Login):
+		name += " " + d.styles.PinnedStyle.Render("[pinned]")
+	case d.lists.Denied(i.user.Login):
+		name += " " + d.styles.IgnoredStyle.Render("[ignored]")
+	}
+
+	state := ""
+	switch d.states[i.user.Login] {
+	case itemPending:
+		state = " " + d.styles.PendingStyle.Render("…")
+	case itemDone:
+		state = " " + d.styles.DoneStyle.Render("✓")
+	case itemFailed:
+		state = " " + d.styles.FailedStyle.Render("✗")
+	}
 
 	if index == m.Index() {
-		fmt.Fprintf(w, "%s%s%s", d.styles.CursorStyle.Render("> "), d.styles.SelectedStyle.Render(str), "\n")
+		fmt.Fprintf(w, "%s%s%s%s%s", d.styles.CursorStyle.Render("> "), check, d.styles.SelectedStyle.Render(str), name, state)
 	} else {
-		fmt.Fprintf(w, "  %s\n", str)
+		fmt.Fprintf(w, "  %s%s%s%s", check, str, name, state)
+	}
+}
+
+// highlight renders the runes of s at the byte positions in matches with style.
+func highlight(s string, matches []int, style lipgloss.Style) string {
+	if len(matches) == 0 {
+		return s
+	}
+	matched := make(map[int]bool, len(matches))
+	for _, k := range matches {
+		matched[k] = true
+	}
+	var b strings.Builder
+	for k, r := range s {
+		if matched[k] {
+			b.WriteString(style.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// renderUserDetail renders the profile fields that are known for a user.
+func renderUserDetail(styles *TUIStyles, d userDetail) string {
+	u := d.user
+	var b strings.Builder
+	b.WriteString(styles.DetailTitle.Render(u.Login))
+	if u.Name != "" {
+		b.WriteString(" " + styles.NameStyle.Render(u.Name))
+	}
+	b.WriteString("\n")
+
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s %s\n", styles.DetailLabel.Render(label), value)
+		}
+	}
+	field("Type:     ", u.Type)
+	if u.SiteAdmin {
+		field("Staff:    ", "GitHub staff")
+	}
+	field("Bio:      ", u.Bio)
+	field("Company:  ", u.Company)
+	field("Location: ", u.Location)
+	if u.Followers > 0 || u.Following > 0 || u.PublicRepos > 0 {
+		field("Counts:   ", fmt.Sprintf("%d followers · %d following · %d repos", u.Followers, u.Following, u.PublicRepos))
+	}
+	if !u.CreatedAt.IsZero() {
+		field("Joined:   ", u.CreatedAt.Format("2006-01-02"))
+	}
+	field("Avatar:   ", u.AvatarURL)
+	switch {
+	case !d.lastActivity.IsZero():
+		field("Active:   ", d.lastActivity.Local().Format("2006-01-02 15:04"))
+	case d.fetched:
+		field("Active:   ", "no recent public activity")
+	}
+	if d.followsYou {
+		field("Follows:  ", "follows you")
+	} else {
+		field("Follows:  ", "does not follow you")
+	}
+	switch {
+	case d.loading:
+		b.WriteString(styles.LoadingStyle.Render("Loading profile...") + "\n")
+	case d.err != nil:
+		b.WriteString(styles.ErrorStyle.Render("Profile unavailable: "+d.err.Error()) + "\n")
 	}
+	return strings.TrimSuffix(b.String(), "\n")
 }
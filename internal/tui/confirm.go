@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gh-mutual-follow/internal/bulk"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultConfirmPreview is the number of affected logins listed in a confirmation dialog.
+const DefaultConfirmPreview = 5
+
+// confirmDialog asks the user to confirm an action before it runs. Single actions are
+// confirmed with y, bulk actions by typing the number of users or "yes".
+type confirmDialog struct {
+	action    bulk.Action
+	logins    []string
+	bulk      bool
+	reverts   string // the audit batch being undone, if any
+	protected int    // users left out because they are pinned or ignored
+	input     string
+}
+
+// confirmResult is the outcome of a key press in a confirmation dialog.
+type confirmResult int
+
+const (
+	confirmPending confirmResult = iota
+	confirmAccepted
+	confirmRejected
+)
+
+// update applies a key press to the dialog.
+func (d *confirmDialog) update(msg tea.KeyMsg) confirmResult {
+	switch msg.Type {
+	case tea.KeyEsc:
+		return confirmRejected
+	case tea.KeyEnter:
+		if d.bulk && !d.inputMatches() {
+			return confirmPending
+		}
+		return confirmAccepted
+	case tea.KeyBackspace:
+		if d.input != "" {
+			d.input = d.input[:len(d.input)-1]
+		}
+	case tea.KeyRunes:
+		if d.bulk {
+			d.input += string(msg.Runes)
+			return confirmPending
+		}
+		switch string(msg.Runes) {
+		case "y", "Y":
+			return confirmAccepted
+		case "n", "N":
+			return confirmRejected
+		}
+	}
+	return confirmPending
+}
+
+// inputMatches reports whether the typed text confirms a bulk action.
+func (d *confirmDialog) inputMatches() bool {
+	input := strings.TrimSpace(d.input)
+	return input == "yes" || input == strconv.Itoa(len(d.logins))
+}
+
+// view renders the dialog, listing at most preview logins.
+func (d *confirmDialog) view(styles *TUIStyles, preview int) string {
+	var b strings.Builder
+	title := fmt.Sprintf("%s %d users?", capitalize(string(d.action)), len(d.logins))
+	if len(d.logins) == 1 {
+		title = fmt.Sprintf("%s %s?", capitalize(string(d.action)), d.logins[0])
+	}
+	if d.reverts != "" {
+		title = "Undo: " + title
+	}
+	fmt.Fprintf(&b, "%s\n\n", styles.DetailTitle.Render(title))
+
+	if len(d.logins) > 1 {
+		shown := d.logins
+		if preview >= 0 && len(shown) > preview {
+			shown = shown[:preview]
+		}
+		for _, login := range shown {
+			fmt.Fprintf(&b, "  %s\n", login)
+		}
+		if more := len(d.logins) - len(shown); more > 0 {
+			fmt.Fprintf(&b, "  %s\n", styles.DetailLabel.Render(fmt.Sprintf("... and %d more", more)))
+		}
+		b.WriteString("\n")
+	}
+	if d.protected > 0 {
+		fmt.Fprintf(&b, "%s\n\n", styles.DetailLabel.Render(fmt.Sprintf("%d %s users left out", d.protected, protectedWord(d.action))))
+	}
+
+	if d.bulk {
+		fmt.Fprintf(&b, "Type %d or yes to confirm: %s%s", len(d.logins), d.input, styles.CursorStyle.Render("█"))
+	} else {
+		b.WriteString("[y] Confirm   [n] Cancel")
+	}
+	return b.String()
+}
+
+// capitalize upper-cases the first letter of an ASCII word.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
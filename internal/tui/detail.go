@@ -0,0 +1,170 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gh-mutual-follow/internal/github"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// profileRestDelay is how long the cursor must rest on a user before the profile is fetched.
+	profileRestDelay = 300 * time.Millisecond
+	// detailColumnMinWidth is the terminal width from which the detail panel is a column
+	// next to the panes. Narrower terminals show it as an overlay instead.
+	detailColumnMinWidth = 120
+	// detailWidth is the width of the detail column.
+	detailWidth = 44
+)
+
+// profile is a cached profile fetch.
+type profile struct {
+	user         github.User
+	lastActivity time.Time
+	loading      bool
+	err          error
+}
+
+// profileCache holds the fetched profiles by login. It is shared by copies of the model.
+type profileCache map[string]*profile
+
+// has reports whether the profile of login is fetched or being fetched. A failed
+// fetch does not count, so it is retried when the cursor comes back to the user.
+func (c profileCache) has(login string) bool {
+	p, ok := c[login]
+	return ok && p.err == nil
+}
+
+// userDetail is what the detail panel knows about a user.
+type userDetail struct {
+	user         github.User
+	lastActivity time.Time
+	followsYou   bool
+	fetched      bool // user and lastActivity come from a profile fetch
+	loading      bool
+	err          error
+}
+
+// profileRestMsg fires once the cursor has rested on login for profileRestDelay.
+type profileRestMsg struct {
+	login string
+}
+
+// profileLoadedMsg carries the result of a profile fetch.
+type profileLoadedMsg struct {
+	login        string
+	user         github.User
+	lastActivity time.Time
+	err          error
+}
+
+// profileRestCmd waits for the cursor to rest on login.
+func profileRestCmd(login string) tea.Cmd {
+	return tea.Tick(profileRestDelay, func(time.Time) tea.Msg {
+		return profileRestMsg{login: login}
+	})
+}
+
+// fetchProfileCmd fetches the profile and last public activity of login.
+func fetchProfileCmd(ctx context.Context, client github.Client, login string) tea.Cmd {
+	return func() tea.Msg {
+		u, err := client.GetUserProfile(ctx, login)
+		if err != nil {
+			return profileLoadedMsg{login: login, err: err}
+		}
+		last, err := client.GetLastActivity(ctx, login)
+		return profileLoadedMsg{login: login, user: u, lastActivity: last, err: err}
+	}
+}
+
+// scheduleProfile starts waiting for the cursor to rest on the selected user,
+// if the detail panel is shown and the profile has not been fetched yet.
+func (m tuiModel) scheduleProfile() tea.Cmd {
+	if !m.showDetail {
+		return nil
+	}
+	u, ok := m.selectedUser()
+	if !ok {
+		return nil
+	}
+	if m.profiles.has(u.Login) {
+		return nil
+	}
+	return profileRestCmd(u.Login)
+}
+
+// updateProfile handles the messages of the lazy profile fetch.
+func (m tuiModel) updateProfile(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case profileRestMsg:
+		u, ok := m.selectedUser()
+		if !m.showDetail || !ok || u.Login != msg.login {
+			return m, nil
+		}
+		if m.profiles.has(msg.login) {
+			return m, nil
+		}
+		m.profiles[msg.login] = &profile{loading: true}
+		return m, fetchProfileCmd(m.ctx, m.client, msg.login)
+
+	case profileLoadedMsg:
+		if errors.Is(msg.err, context.Canceled) {
+			delete(m.profiles, msg.login) // fetch again next time
+			return m, nil
+		}
+		m.profiles[msg.login] = &profile{user: msg.user, lastActivity: msg.lastActivity, err: msg.err}
+		if m.search != nil {
+			m.applySearch() // the profile may match the search now
+		}
+	}
+	return m, nil
+}
+
+// selectedDetail returns what is known about the user under the cursor.
+func (m tuiModel) selectedDetail() (userDetail, bool) {
+	u, ok := m.selectedUser()
+	if !ok {
+		return userDetail{}, false
+	}
+	d := userDetail{user: u, followsYou: m.followers[u.Login], loading: true}
+	if p := m.profiles[u.Login]; p != nil {
+		d.loading = p.loading
+		d.err = p.err
+		if !p.loading && p.err == nil {
+			d.user = p.user
+			d.lastActivity = p.lastActivity
+			d.fetched = true
+		}
+	}
+	return d, true
+}
+
+// detailColumn reports whether the detail panel is shown as a column next to the panes.
+func (m tuiModel) detailColumn() bool {
+	return m.showDetail && m.width >= detailColumnMinWidth
+}
+
+// detailView renders the profile of the user under the cursor, as a column next to
+// the panes or in their place.
+func (m tuiModel) detailView(l layout) string {
+	style := m.styles.Pane
+	if m.width > 0 {
+		width, height := m.width, l.paneHeight
+		if l.stacked {
+			height += (len(m.panes) - 1) * collapsedPaneHeight
+		}
+		if m.detailColumn() {
+			width = l.detailWidth
+		}
+		style = style.Width(width - style.GetHorizontalBorderSize()).
+			Height(max(height-style.GetVerticalBorderSize(), 0))
+	}
+	d, ok := m.selectedDetail()
+	if !ok {
+		return style.Render(m.styles.NoItemsStyle.Render("No user selected"))
+	}
+	return style.Render(renderUserDetail(m.styles, d))
+}
@@ -0,0 +1,117 @@
+package tui
+
+import "github.com/charmbracelet/lipgloss"
+
+const (
+	// stackedMaxWidth is the terminal width below which the panes are stacked vertically.
+	stackedMaxWidth = 90
+	// headerHeight is the number of lines above the panes.
+	headerHeight = 1
+	// statusHeight is the number of lines below the help line.
+	statusHeight = 1
+	// collapsedPaneHeight is the outer height of a stacked pane that is not focused:
+	// its title between two borders.
+	collapsedPaneHeight = 3
+)
+
+// layout is the size of the panes and lists for a terminal size.
+type layout struct {
+	stacked     bool // the panes are stacked vertically
+	paneWidth   int  // outer width of a pane, border included
+	paneHeight  int  // outer height of a pane, border included; of the focused pane when stacked
+	detailWidth int  // outer width of the detail column, or 0 when it is not shown as a column
+	listWidth   int
+	listHeight  int
+}
+
+// layout derives the size of the panes from the terminal size. The header, the help
+// and status lines and the border and padding of every pane are taken off first.
+func (m tuiModel) layout() layout {
+	var l layout
+	l.stacked = m.width < stackedMaxWidth
+	if m.detailColumn() {
+		l.detailWidth = detailWidth
+	}
+	style := m.paneStyle(l, false)
+	frameWidth, frameHeight := style.GetFrameSize()
+
+	available := m.height - headerHeight - lipgloss.Height(m.helpView()) - statusHeight
+	if m.search != nil {
+		available -= searchHeight
+	}
+	n := len(m.panes)
+	if l.stacked {
+		// Only the focused pane shows its list, the others are collapsed to their title.
+		l.paneWidth = m.width
+		l.paneHeight = available - (n-1)*collapsedPaneHeight
+	} else {
+		l.paneWidth = (m.width - l.detailWidth) / n
+		l.paneHeight = available
+	}
+	l.listWidth = max(l.paneWidth-frameWidth, 1)
+	l.listHeight = max(l.paneHeight-frameHeight-1, 1) // 1 for the pane title
+	return l
+}
+
+// paneStyle returns the style of a pane sized for l. Stacked panes drop the vertical
+// padding to leave room for the lists.
+func (m tuiModel) paneStyle(l layout, focused bool) lipgloss.Style {
+	style := m.styles.Pane
+	if focused {
+		style = m.styles.FocusedPane
+	}
+	if l.stacked {
+		style = style.Padding(0, 1)
+	}
+	if l.paneWidth > 0 {
+		height := l.paneHeight
+		if l.stacked && !focused {
+			height = collapsedPaneHeight
+		}
+		// Width and Height include the padding but not the border.
+		style = style.Width(l.paneWidth - style.GetHorizontalBorderSize()).
+			Height(max(height-style.GetVerticalBorderSize(), 0))
+	}
+	return style
+}
+
+// paneView renders pane i sized for l.
+func (m tuiModel) paneView(l layout, i int) string {
+	p := m.panes[i]
+	focused := i == m.activePane
+	title := lipgloss.NewStyle().Bold(true).Render(p.titleView())
+	if l.stacked && !focused {
+		return m.paneStyle(l, false).Render(title)
+	}
+	listView := p.list.View()
+	if m.width > 0 {
+		listView = clipHeight(listView, l.listHeight)
+	}
+	return m.paneStyle(l, focused).Render(lipgloss.JoinVertical(lipgloss.Left, title, listView))
+}
+
+// resizePanes sizes the lists for the current terminal size.
+func (m *tuiModel) resizePanes() {
+	if m.width == 0 {
+		return // no WindowSizeMsg yet
+	}
+	l := m.layout()
+	for i := range m.panes {
+		m.panes[i].list.SetSize(l.listWidth, l.listHeight)
+	}
+}
+
+// clipHeight cuts s to at most height lines, so a list squeezed below the size of its
+// status bar and pagination does not push the panes out of the terminal.
+func clipHeight(s string, height int) string {
+	return lipgloss.NewStyle().MaxHeight(height).Render(s)
+}
+
+// helpView renders the key bindings, wrapped to the terminal width.
+func (m tuiModel) helpView() string {
+	style := m.styles.HelpStyle
+	if m.width > 0 {
+		style = style.Width(m.width)
+	}
+	return style.Render("[q] Quit   [↑↓] Move   [←→] Page   [tab] Switch Pane   [/] Search   [r] Refresh   [enter] Action   [space] Select   [*] Invert   [v] Select Visible   [x] Clear   [a] Action Selected/All   [p] Pin   [i] Ignore   [u] Undo   [C] Changes   [d] Detail   [o] Open   [y/Y] Copy Login/URL   [esc] Cancel")
+}
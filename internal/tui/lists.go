@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"fmt"
+
+	"gh-mutual-follow/internal/bulk"
+	"gh-mutual-follow/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// requestBulk leaves the users protected by the allowlist or denylist out of a bulk
+// action, then asks to confirm it or starts it.
+func (m tuiModel) requestBulk(action bulk.Action, logins []string) (tea.Model, tea.Cmd) {
+	logins, protected := m.lists.Filter(action, logins)
+	if len(logins) == 0 {
+		m.statusMessage = fmt.Sprintf("Nothing to %s: every user is %s", action, protectedWord(action))
+		return m, clearStatusMsg()
+	}
+	return m.askBulk(action, logins, "", len(protected))
+}
+
+// askBulk asks to confirm a bulk action, or starts it when confirmation is off.
+// reverts names the audit batch being undone, if any; protected counts the users
+// the lists left out.
+func (m tuiModel) askBulk(action bulk.Action, logins []string, reverts string, protected int) (tea.Model, tea.Cmd) {
+	if m.confirmBulk {
+		m.confirm = &confirmDialog{action: action, logins: logins, bulk: true, reverts: reverts, protected: protected}
+		return m, nil
+	}
+	return m.startBulk(action, logins, reverts)
+}
+
+// protectedWord is how the users a list protects from action are called.
+func protectedWord(action bulk.Action) string {
+	if action == bulk.Unfollow {
+		return "pinned"
+	}
+	return "ignored"
+}
+
+// togglePin adds login to the allowlist, so that bulk actions never unfollow them,
+// or takes them off it.
+func (m tuiModel) togglePin(login string) (tea.Model, tea.Cmd) {
+	status := fmt.Sprintf("Unpinned %s", login)
+	if m.lists.TogglePin(login) {
+		status = fmt.Sprintf("Pinned %s: bulk actions will not unfollow them", login)
+	}
+	return m, saveConfigCmd(m.config, *m.lists, status)
+}
+
+// toggleIgnore adds login to the denylist, so that bulk actions never follow them
+// back, or takes them off it.
+func (m tuiModel) toggleIgnore(login string) (tea.Model, tea.Cmd) {
+	status := fmt.Sprintf("Unignored %s", login)
+	if m.lists.ToggleIgnore(login) {
+		status = fmt.Sprintf("Ignored %s: bulk actions will not follow them", login)
+	}
+	return m, saveConfigCmd(m.config, *m.lists, status)
+}
+
+// saveConfigCmd writes the lists to the config file, then shows status. Without a
+// file the lists only last until the TUI quits.
+func saveConfigCmd(f *config.File, c config.Config, status string) tea.Cmd {
+	return func() tea.Msg {
+		if f == nil {
+			return statusMsg(status)
+		}
+		if err := f.Save(c); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to save the config: %v", err))
+		}
+		return statusMsg(status)
+	}
+}
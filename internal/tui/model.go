@@ -1,66 +1,193 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"time"
 
+	"gh-mutual-follow/internal/audit"
+	"gh-mutual-follow/internal/bulk"
+	"gh-mutual-follow/internal/config"
 	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/history"
+	"gh-mutual-follow/internal/store"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Panes, in tab order.
 const (
 	followingPane = iota
 	followersPane
+	mutualPane
 )
 
+// pane is a list of users and the action that enter and a apply to them.
+type pane struct {
+	title    string
+	action   bulk.Action
+	list     list.Model
+	items    []list.Item // all the users of the pane; the list shows those matching the search
+	selected selection   // checked users, shared with the list delegate
+}
+
+// newPane creates an empty pane. states and lists are shared by every pane.
+func newPane(title string, action bulk.Action, styles *TUIStyles, states itemStates, lists *config.Config) pane {
+	selected := selection{}
+	l := list.New([]list.Item{}, itemDelegate{styles: styles, selected: selected, states: states, lists: lists}, 0, 0)
+	l.SetShowTitle(false)
+	l.KeyMap = list.DefaultKeyMap()
+	l.SetShowHelp(false)         // the footer lists the key bindings
+	l.SetFilteringEnabled(false) // the search bar filters the panes instead
+	return pane{title: title, action: action, list: l, selected: selected}
+}
+
+// setItems replaces the users of the pane, unchecking those that left it.
+// The model applies the search afterwards.
+func (p *pane) setItems(items []list.Item) {
+	p.items = items
+	p.list.SetItems(items)
+	p.selected.prune(items)
+}
+
+// titleView returns the title with the number of checked users, if any.
+func (p pane) titleView() string {
+	if len(p.selected) == 0 {
+		return p.title
+	}
+	return fmt.Sprintf("%s (%d selected)", p.title, len(p.selected))
+}
+
 // model represents the state of the TUI.
 type tuiModel struct {
 	client                 github.Client
+	ctx                    context.Context // cancelled when the TUI quits
+	cancel                 context.CancelFunc
+	opCtx                  context.Context // cancelled by Esc to abort the current load or bulk action
+	opCancel               context.CancelFunc
 	username               string
-	onlyFollowing          []list.Item
-	onlyFollowers          []list.Item
+	panes                  []pane
 	activePane             int
-	followingList          list.Model
-	followersList          list.Model
 	loading                bool
+	refreshing             bool // cached data is shown while the fresh lists load
 	err                    error
 	quitting               bool
 	styles                 *TUIStyles
 	statusMessage          string
 	isBulkActionInProgress bool
+	executor               *bulk.Executor
+	bulkAction             bulk.Action    // the action of the running bulk operation
+	bulkCh                 <-chan tea.Msg // progress of the running bulk action
+	bulkProgress           bulk.Progress
+	bulkReport             *bulk.Report // shown after a bulk action until dismissed
+	progress               progress.Model
+	showDetail             bool
+	rateLimit              github.RateLimit
+	pauseUntil             time.Time
 	width, height          int
+	dryRun                 bool                  // follow and unfollow are only simulated
+	auditLog               *audit.Log            // journal of past actions, used by undo
+	confirm                *confirmDialog        // shown until the pending action is confirmed or cancelled
+	confirmSingle          bool                  // single unfollows ask for confirmation
+	confirmBulk            bool                  // bulk actions ask for confirmation
+	confirmPreview         int                   // logins listed in a confirmation dialog
+	profiles               profileCache          // profiles fetched for the detail panel
+	followers              map[string]bool       // logins that follow the account
+	opener                 Opener                // opens profile URLs
+	clipboard              Clipboard             // copies logins and URLs
+	webHost                string                // host of profile URLs
+	search                 *searchBar            // shown while the panes are searched
+	history                history.Store         // snapshots of the relationships, for the Changes view
+	store                  store.Store           // cache of the last load, shown at start
+	cache                  *github.CachingClient // invalidated by an explicit refresh
+	states                 itemStates            // progress of single actions, shown in the panes
+	lists                  *config.Config        // allowlist and denylist, shared with the list delegates
+	config                 *config.File          // where pins and ignores are saved
+	changes                *changesView          // shown until dismissed
 }
 
-// NewModel creates the initial model for the TUI application.
+// NewModel creates the initial model for the TUI application using the gh CLI backed client.
 func NewModel() tea.Model {
+	return NewModelWithClient(github.NewClient())
+}
+
+// NewModelWithClient creates the initial model for the TUI application with the given client.
+func NewModelWithClient(client github.Client, opts ...Option) tea.Model {
 	styles := defaultStyles()
-	client := github.NewClient()
-
-	// Create delegates
-	followingDelegate := itemDelegate{styles: styles}
-	followersDelegate := itemDelegate{styles: styles}
-
-	// Create lists
-	followingList := list.New([]list.Item{}, followingDelegate, 0, 0)
-	followersList := list.New([]list.Item{}, followersDelegate, 0, 0)
-
-	followingList.SetShowTitle(false)
-	followersList.SetShowTitle(false)
-	followingList.KeyMap = list.DefaultKeyMap()
-	followersList.KeyMap = list.DefaultKeyMap()
-	followingList.Paginator.PerPage = 10
-	followersList.Paginator.PerPage = 10
-
-	return tuiModel{
-		client:        client,
-		activePane:    followingPane,
-		followingList: followingList,
-		followersList: followersList,
-		loading:       true,
-		styles:        styles,
+
+	// Unfollowing is available from the mutual pane too, for people who follow back.
+	states := itemStates{}
+	lists := &config.Config{}
+	panes := []pane{
+		followingPane: newPane("Following", bulk.Unfollow, styles, states, lists),
+		followersPane: newPane("Followers", bulk.Follow, styles, states, lists),
+		mutualPane:    newPane("Mutual", bulk.Unfollow, styles, states, lists),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := tuiModel{
+		client:         client,
+		ctx:            ctx,
+		cancel:         cancel,
+		panes:          panes,
+		activePane:     followingPane,
+		loading:        true,
+		styles:         styles,
+		executor:       bulk.NewExecutor(client),
+		progress:       progress.New(progress.WithDefaultGradient()),
+		confirmSingle:  true,
+		confirmBulk:    true,
+		confirmPreview: DefaultConfirmPreview,
+		profiles:       make(profileCache),
+		states:         states,
+		lists:          lists,
+		opener:         BrowserOpener,
+		clipboard:      OSC52Clipboard(os.Stderr),
+		webHost:        DefaultWebHost,
+	}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	m.startOperation()
+	return m
+}
+
+// startOperation cancels any outstanding operation and prepares a fresh context for a new one.
+func (m *tuiModel) startOperation() context.Context {
+	if m.opCancel != nil {
+		m.opCancel()
 	}
+	m.opCtx, m.opCancel = context.WithCancel(m.ctx)
+	return m.opCtx
+}
+
+// startBulk runs action for every login in the background, streaming progress to the model.
+// The calls are audited as one batch; reverts names the batch being undone, if any.
+func (m tuiModel) startBulk(action bulk.Action, logins []string, reverts string) (tea.Model, tea.Cmd) {
+	m.isBulkActionInProgress = true
+	m.bulkAction = action
+	m.bulkProgress = bulk.Progress{Total: len(logins)}
+	m.statusMessage = ""
+	ch := make(chan tea.Msg)
+	m.bulkCh = ch
+	ctx := audit.WithBatch(m.startOperation(), audit.NewBatchID())
+	if reverts != "" {
+		ctx = audit.WithReverts(ctx, reverts)
+	}
+	go runBulk(ctx, m.executor, action, logins, ch)
+	return m, waitForBulkMsg(ch)
+}
+
+// quit cancels all in-flight work and exits the program.
+func (m tuiModel) quit() (tea.Model, tea.Cmd) {
+	m.quitting = true
+	m.cancel()
+	return m, tea.Quit
 }
 
 // Msgs for async operations
@@ -68,6 +195,8 @@ type dataLoadedMsg struct {
 	username      string
 	onlyFollowing []list.Item
 	onlyFollowers []list.Item
+	mutual        []list.Item
+	cached        bool // loaded from the store, not from GitHub
 	err           error
 }
 
@@ -75,8 +204,22 @@ type errorMsg struct{ err error }
 
 type statusMsg string
 
+// RateLimitMsg reports the client's API quota or a rate limit pause to the TUI.
+// Send it from a github.WithRateLimitObserver callback via tea.Program.Send.
+type RateLimitMsg github.RateLimitEvent
+
+// CacheRevalidatedMsg tells the TUI that a caching client refreshed a stale list in
+// the background. Send it from a github.WithRevalidateObserver callback via tea.Program.Send.
+type CacheRevalidatedMsg struct{}
+
+// pauseTickMsg refreshes the rate limit pause countdown.
+type pauseTickMsg struct{}
+
 func (m tuiModel) Init() tea.Cmd {
-	return loadDataCmd(m.client)
+	if m.store != nil {
+		return tea.Batch(loadCachedCmd(m.store), loadDataCmd(m.opCtx, m.client))
+	}
+	return loadDataCmd(m.opCtx, m.client)
 }
 
 func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -86,35 +229,116 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		const listHeight = 15 // Trial-and-error to get 10 items to display
-
-		listWidth := msg.Width / 2
-
-		m.followingList.SetHeight(listHeight)
-		m.followersList.SetHeight(listHeight)
-		m.followingList.SetWidth(listWidth)
-		m.followersList.SetWidth(listWidth)
+		m.resizePanes()
 		return m, nil
 	case dataLoadedMsg:
+		if msg.cached && !m.loading {
+			return m, nil // the fresh lists arrived first
+		}
 		m.loading = false
+		m.refreshing = msg.cached
 		if msg.err != nil {
 			m.err = msg.err
 			return m, nil
 		}
 		m.username = msg.username
-		m.onlyFollowing = msg.onlyFollowing
-		m.onlyFollowers = msg.onlyFollowers
+		m.panes[followingPane].setItems(msg.onlyFollowing)
+		m.panes[followersPane].setItems(msg.onlyFollowers)
+		m.panes[mutualPane].setItems(msg.mutual)
+		m.applySearch()
+		m.states.forgetSettled()
+		m.followers = make(map[string]bool)
+		for _, items := range [][]list.Item{msg.onlyFollowers, msg.mutual} {
+			for _, li := range items {
+				m.followers[li.(item).user.Login] = true
+			}
+		}
+		cmd := m.scheduleProfile()
+		if msg.cached || m.dryRun {
+			return m, cmd
+		}
+		if m.history != nil {
+			cmd = tea.Batch(cmd, saveSnapshotCmd(m.history, snapshotOf(msg)))
+		}
+		if m.store != nil {
+			cmd = tea.Batch(cmd, saveUsersCmd(m.store, msg))
+		}
+		return m, cmd
+
+	case profileRestMsg, profileLoadedMsg:
+		return m.updateProfile(msg)
 
-		m.followingList.SetItems(m.onlyFollowing)
-		m.followersList.SetItems(m.onlyFollowers)
+	case singleDoneMsg:
+		return m.updateSingle(msg)
 
 	case errorMsg:
 		m.loading = false
+		if errors.Is(msg.err, context.Canceled) {
+			if m.quitting {
+				return m, nil
+			}
+			m.refreshing = false
+			m.statusMessage = "Cancelled"
+			return m, clearStatusMsg()
+		}
+		if m.refreshing {
+			// Keep showing the cached lists rather than an error screen.
+			m.refreshing = false
+			m.statusMessage = fmt.Sprintf("Refresh failed: %v", msg.err)
+			return m, nil
+		}
 		m.err = msg.err
 		return m, nil
 
-	case statusMsg:
+	case RateLimitMsg:
+		if msg.RateLimit.Known() {
+			m.rateLimit = msg.RateLimit
+		}
+		if msg.Pause > 0 {
+			startTicking := !time.Now().Before(m.pauseUntil)
+			m.pauseUntil = msg.Until
+			if startTicking {
+				return m, pauseTickCmd()
+			}
+		}
+		return m, nil
+
+	case CacheRevalidatedMsg:
+		// Show the new lists, read from the cache, without the loading screen.
+		// It is not tied to the operation context, which Esc may have cancelled already.
+		if m.loading || m.isBulkActionInProgress {
+			return m, nil
+		}
+		return m, loadDataCmd(m.ctx, m.client)
+
+	case pauseTickMsg:
+		if time.Now().Before(m.pauseUntil) {
+			return m, pauseTickCmd()
+		}
+		return m, nil
+
+	case bulkProgressMsg:
+		m.bulkProgress = msg.progress
+		return m, waitForBulkMsg(m.bulkCh)
+
+	case bulkDoneMsg:
 		m.isBulkActionInProgress = false
+		m.bulkCh = nil
+		m.statusMessage = ""
+		m.bulkReport = &msg.report
+		return m, nil
+
+	case undoMsg:
+		// An undo puts the users back as they were: the lists must not leave any out.
+		return m.askBulk(msg.action, msg.logins, msg.batch, 0)
+
+	case changesMsg:
+		c := changesView(msg)
+		m.changes = &c
+		return m, nil
+
+	case statusMsg:
+		// Only bulkDoneMsg ends a bulk action: a status can arrive while one runs.
 		m.statusMessage = string(msg)
 		if m.statusMessage != "" {
 			return m, clearStatusMsg() // Start timer to clear message
@@ -123,106 +347,203 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		if m.isBulkActionInProgress {
-			if msg.String() == "q" || msg.String() == "ctrl+c" {
-				m.quitting = true
-				return m, tea.Quit
+			switch msg.String() {
+			case "q", "ctrl+c":
+				return m.quit()
+			case "esc":
+				m.opCancel()
+				m.statusMessage = "Cancelling..."
 			}
 			return m, nil
 		}
 
 		if m.err != nil {
 			if msg.String() == "q" || msg.String() == "ctrl+c" {
-				m.quitting = true
-				return m, tea.Quit
+				return m.quit()
+			}
+			return m, nil
+		}
+
+		if m.confirm != nil {
+			if msg.String() == "ctrl+c" {
+				return m.quit()
+			}
+			d := m.confirm
+			switch d.update(msg) {
+			case confirmAccepted:
+				m.confirm = nil
+				if d.bulk {
+					return m.startBulk(d.action, d.logins, d.reverts)
+				}
+				return m.runSingle(d.action, d.logins[0])
+			case confirmRejected:
+				m.confirm = nil
+				m.statusMessage = "Cancelled"
+				return m, clearStatusMsg()
+			}
+			return m, nil
+		}
+
+		if m.bulkReport != nil {
+			switch msg.String() {
+			case "q", "ctrl+c":
+				return m.quit()
+			case "R":
+				if failed := m.bulkReport.Failed(); len(failed) > 0 {
+					action := m.bulkReport.Action
+					m.bulkReport = nil
+					return m.startBulk(action, failed, "")
+				}
+			case "enter", "esc":
+				m.bulkReport = nil
+				m.loading = true
+				return m, loadDataCmd(m.startOperation(), m.client)
+			}
+			return m, nil
+		}
+
+		if m.changes != nil {
+			switch msg.String() {
+			case "q", "ctrl+c":
+				return m.quit()
+			case "tab":
+				return m, changesCmd(m.history, m.currentSnapshot(), (m.changes.window+1)%len(changesWindows))
+			case "enter", "esc", "C":
+				m.changes = nil
 			}
 			return m, nil
 		}
 
+		if m.search != nil && m.search.focused {
+			return m.updateSearch(msg)
+		}
+
 		var cmd tea.Cmd
 		switch msg.String() {
 		case "q", "ctrl+c":
-			m.quitting = true
-			return m, tea.Quit
-		case "tab", "shift+tab":
-			if m.activePane == followingPane {
-				m.activePane = followersPane
-			} else {
-				m.activePane = followingPane
+			return m.quit()
+		case "esc":
+			// Abort a load in progress, or else clear the search.
+			// Esc is never forwarded to the list, which would quit.
+			if m.loading || m.refreshing {
+				m.opCancel()
+			} else if m.search != nil {
+				m.search = nil
+				m.applySearch()
+				m.resizePanes()
+			}
+			return m, nil
+		case "/":
+			if m.search == nil {
+				m.search = newSearchBar(m.activePane)
+				m.resizePanes()
+			}
+			m.search.focused = true
+			return m, m.search.input.Focus()
+		case "tab":
+			m.activePane = (m.activePane + 1) % len(m.panes)
+			return m, m.scheduleProfile()
+		case "shift+tab":
+			m.activePane = (m.activePane + len(m.panes) - 1) % len(m.panes)
+			return m, m.scheduleProfile()
+		case "d":
+			m.showDetail = !m.showDetail
+			m.resizePanes()
+			return m, m.scheduleProfile()
+		case "o":
+			if u, ok := m.selectedUser(); ok {
+				return m, openCmd(m.opener, profileURL(m.webHost, u.Login))
+			}
+			return m, nil
+		case "y":
+			if u, ok := m.selectedUser(); ok {
+				return m, copyCmd(m.clipboard, u.Login)
+			}
+			return m, nil
+		case "Y":
+			if u, ok := m.selectedUser(); ok {
+				return m, copyCmd(m.clipboard, profileURL(m.webHost, u.Login))
 			}
 			return m, nil
 		case "r":
+			if m.cache != nil {
+				m.cache.Invalidate()
+			}
 			m.loading = true
 			m.err = nil
-			return m, loadDataCmd(m.client)
+			return m, loadDataCmd(m.startOperation(), m.client)
 		case "enter":
-			var selectedItem item
-			var actionCmd tea.Cmd
-
-			if m.activePane == followingPane {
-				if i := m.followingList.SelectedItem(); i != nil {
-					selectedItem = i.(item)
-					actionCmd = func() tea.Msg {
-						err := m.client.Unfollow(string(selectedItem))
-						if err != nil {
-							return errorMsg{fmt.Errorf("failed to unfollow %s: %w", selectedItem, err)}
-						}
-						return statusMsg(fmt.Sprintf("Unfollowed %s!", selectedItem))
-					}
-				}
-			} else { // Followers pane
-				if i := m.followersList.SelectedItem(); i != nil {
-					selectedItem = i.(item)
-					actionCmd = func() tea.Msg {
-						err := m.client.Follow(string(selectedItem))
-						if err != nil {
-							return errorMsg{fmt.Errorf("failed to follow %s: %w", selectedItem, err)}
-						}
-						return statusMsg(fmt.Sprintf("Followed %s!", selectedItem))
-					}
-				}
+			u, ok := m.selectedUser()
+			if !ok {
+				return m, nil
 			}
-
-			if actionCmd != nil {
-				m.loading = true
-				return m, tea.Batch(actionCmd, loadDataCmd(m.client))
+			action := m.panes[m.activePane].action
+			if action == bulk.Unfollow && m.confirmSingle {
+				m.confirm = &confirmDialog{action: action, logins: []string{u.Login}}
+				return m, nil
 			}
-		case "a": // Bulk action
-			var items []list.Item
-			var action string
-			if m.activePane == followingPane {
-				items = m.followingList.Items()
-				action = "unfollow"
-			} else {
-				items = m.followersList.Items()
-				action = "follow"
+			return m.runSingle(action, u.Login)
+		case " ":
+			if u, ok := m.selectedUser(); ok {
+				m.panes[m.activePane].selected.toggle(u.Login)
 			}
-
-			if len(items) == 0 {
-				return m, nil
+			return m, nil
+		case "*":
+			p := m.panes[m.activePane]
+			for _, li := range p.list.VisibleItems() {
+				p.selected.toggle(li.(item).user.Login)
 			}
-
-			m.isBulkActionInProgress = true
-			m.statusMessage = fmt.Sprintf("Bulk %sing all users...", action)
-
-			return m, func() tea.Msg {
-				for _, i := range items {
-					user := i.(item)
-					if action == "unfollow" {
-						_ = m.client.Unfollow(string(user)) // Errors are ignored for now in bulk action
-					} else {
-						_ = m.client.Follow(string(user))
-					}
+			return m, nil
+		case "v":
+			p := m.panes[m.activePane]
+			for _, li := range p.list.VisibleItems() {
+				p.selected[li.(item).user.Login] = true
+			}
+			return m, nil
+		case "x":
+			m.panes[m.activePane].selected.clear()
+			return m, nil
+		case "a": // Bulk action on the selection, or on the visible users when nothing is selected
+			p := m.panes[m.activePane]
+			action := p.action
+			// The selection counts even where the search hides it: falling back to the
+			// visible users would act on people who were never checked.
+			logins := p.selected.logins(p.items)
+			if len(p.selected) == 0 {
+				for _, i := range p.list.Items() {
+					logins = append(logins, i.(item).user.Login)
 				}
-				m.isBulkActionInProgress = false // Reset after completion
-				return statusMsg(fmt.Sprintf("Bulk %s complete!", action))
 			}
-		default: // Forward other keys (like arrows) to the active list
-			if m.activePane == followingPane {
-				m.followingList, cmd = m.followingList.Update(msg)
-			} else {
-				m.followersList, cmd = m.followersList.Update(msg)
+			if len(logins) == 0 {
+				return m, nil
+			}
+			return m.requestBulk(action, logins)
+		case "p":
+			if u, ok := m.selectedUser(); ok {
+				return m.togglePin(u.Login)
 			}
-			cmds = append(cmds, cmd)
+			return m, nil
+		case "i":
+			if u, ok := m.selectedUser(); ok {
+				return m.toggleIgnore(u.Login)
+			}
+			return m, nil
+		case "C":
+			if m.history == nil {
+				m.statusMessage = "Changes are not available without the snapshot history"
+				return m, clearStatusMsg()
+			}
+			return m, changesCmd(m.history, m.currentSnapshot(), defaultChangesWindow)
+		case "u":
+			if m.auditLog == nil {
+				m.statusMessage = "Undo is not available without the audit log"
+				return m, clearStatusMsg()
+			}
+			return m, undoCmd(m.auditLog, m.username)
+		default: // Forward other keys (like arrows) to the active list
+			p := &m.panes[m.activePane]
+			p.list, cmd = p.list.Update(msg)
+			cmds = append(cmds, cmd, m.scheduleProfile())
 		}
 	}
 
@@ -235,48 +556,82 @@ func (m tuiModel) View() string {
 	}
 
 	if m.loading {
-		return m.styles.LoadingStyle.Render("Loading data...") + "\n"
+		return m.styles.LoadingStyle.Render("Loading data...") + "\n" +
+			m.styles.HelpStyle.Render("[esc] Cancel   [q] Quit") + "\n"
 	}
 
 	if m.err != nil {
-		return m.styles.ErrorStyle.Render("Error: " + m.err.Error()) + "\n" +
+		return m.styles.ErrorStyle.Render("Error: "+m.err.Error()) + "\n" +
 			m.styles.HelpStyle.Render("[q] to quit") + "\n"
 	}
 
-	headerView := m.styles.Header.Width(m.width).Render(fmt.Sprintf("GitHub Account : %s", m.username))
-	helpView := m.styles.HelpStyle.Render("[q] Quit   [↑↓] Move   [←→] Page   [tab] Switch Pane   [r] Refresh   [enter] Action   [a] Action All")
+	if m.confirm != nil {
+		return m.styles.FocusedPane.Height(0).Render(m.confirm.view(m.styles, m.confirmPreview)) + "\n" +
+			m.styles.HelpStyle.Render("[enter] Confirm   [esc] Cancel")
+	}
+
+	if m.bulkReport != nil {
+		help := "[enter] Close   [q] Quit"
+		if m.bulkReport.Count(bulk.Failed) > 0 {
+			help = "[R] Retry failed   " + help
+		}
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.styles.FocusedPane.Height(0).Render(renderBulkSummary(m.styles, *m.bulkReport)),
+			m.styles.HelpStyle.Render(help),
+		)
+	}
+
+	if m.changes != nil {
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.styles.FocusedPane.Height(0).Render(renderChanges(m.styles, *m.changes)),
+			m.styles.HelpStyle.Render("[tab] Period   [enter] Close   [q] Quit"),
+		)
+	}
+
+	header := fmt.Sprintf("GitHub Account : %s", m.username)
+	if m.dryRun {
+		header += "   " + m.styles.DryRunBadge.Render("DRY RUN")
+	}
+	if m.refreshing {
+		header += "   " + m.styles.LoadingStyle.Render("Refreshing...")
+	}
+	headerView := m.styles.Header.Width(m.width).Render(header)
+	helpView := m.helpView()
 	statusView := ""
 	if m.isBulkActionInProgress {
-		statusView = m.styles.StatusMessage.Render("Working...")
+		statusView = m.renderBulkProgress()
 	} else if m.statusMessage != "" {
 		statusView = m.styles.StatusMessage.Render(m.statusMessage)
 	}
 
+	if rl := m.rateLimitView(); rl != "" {
+		statusView = lipgloss.JoinHorizontal(lipgloss.Top, statusView, rl)
+	}
+
 	footerView := lipgloss.JoinVertical(lipgloss.Left, helpView, statusView)
 
 	// Render panes
-	followingTitle := "Following"
-	followersTitle := "Followers"
-
-	followingContent := lipgloss.JoinVertical(lipgloss.Left,
-		lipgloss.NewStyle().Bold(true).Render(followingTitle),
-		m.followingList.View(),
-	)
-	followersContent := lipgloss.JoinVertical(lipgloss.Left,
-		lipgloss.NewStyle().Bold(true).Render(followersTitle),
-		m.followersList.View(),
-	)
-
-	var leftPane, rightPane string
-	if m.activePane == followingPane {
-		leftPane = m.styles.FocusedPane.Render(followingContent)
-		rightPane = m.styles.Pane.Render(followersContent)
-	} else {
-		leftPane = m.styles.Pane.Render(followingContent)
-		rightPane = m.styles.FocusedPane.Render(followersContent)
+	l := m.layout()
+	paneViews := make([]string, len(m.panes))
+	for i := range m.panes {
+		paneViews[i] = m.paneView(l, i)
 	}
 
-	content := lipgloss.JoinHorizontal(lipgloss.Top, leftPane, rightPane)
+	// The detail panel is a column next to the panes when there is room, and replaces them otherwise.
+	if m.detailColumn() {
+		paneViews = append(paneViews, m.detailView(l))
+	}
+	join := lipgloss.JoinHorizontal
+	if l.stacked {
+		join = lipgloss.JoinVertical
+	}
+	content := join(lipgloss.Top, paneViews...)
+	if m.showDetail && !m.detailColumn() {
+		content = m.detailView(l)
+	}
+	if m.search != nil {
+		content = lipgloss.JoinVertical(lipgloss.Left, m.searchView(), content)
+	}
 
 	return lipgloss.JoinVertical(lipgloss.Left,
 		headerView,
@@ -284,3 +639,26 @@ func (m tuiModel) View() string {
 		footerView,
 	)
 }
+
+// selectedUser returns the user under the cursor in the active pane.
+func (m tuiModel) selectedUser() (github.User, bool) {
+	i, ok := m.panes[m.activePane].list.SelectedItem().(item)
+	if !ok {
+		return github.User{}, false
+	}
+	return i.user, true
+}
+
+// rateLimitView renders the remaining API quota and any rate limit pause countdown.
+func (m tuiModel) rateLimitView() string {
+	var parts []string
+	if remaining := time.Until(m.pauseUntil); remaining > 0 {
+		parts = append(parts, m.styles.RateLimitPause.Render(
+			fmt.Sprintf("Rate limited, resuming in %s", remaining.Round(time.Second))))
+	}
+	if m.rateLimit.Known() {
+		parts = append(parts, m.styles.StatusMessage.Render(
+			fmt.Sprintf("API quota %d/%d (resets %s)", m.rateLimit.Remaining, m.rateLimit.Limit, m.rateLimit.Reset.Format("15:04"))))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultWebHost is the host profile links point to unless WithWebHost is used.
+const DefaultWebHost = "github.com"
+
+// Opener opens a URL for the user, typically in a web browser.
+type Opener func(url string) error
+
+// Clipboard copies text to the user's clipboard.
+type Clipboard func(text string) error
+
+// ErrNoBrowser is returned by an Opener that found no way to open a URL.
+// The TUI then shows the URL in the status bar instead.
+var ErrNoBrowser = errors.New("no browser available")
+
+// BrowserOpener opens url with the command in $BROWSER, or else with the platform
+// opener (xdg-open, open or rundll32). It does not wait for the browser to exit.
+func BrowserOpener(url string) error {
+	var name string
+	var args []string
+	if browser := strings.Fields(os.Getenv("BROWSER")); len(browser) > 0 {
+		name, args = browser[0], browser[1:]
+	} else {
+		switch runtime.GOOS {
+		case "darwin":
+			name = "open"
+		case "windows":
+			name, args = "rundll32", []string{"url.dll,FileProtocolHandler"}
+		default:
+			name = "xdg-open"
+		}
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNoBrowser, err)
+	}
+	cmd := exec.Command(path, append(args, url)...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", name, err)
+	}
+	go cmd.Wait() // reap the process
+	return nil
+}
+
+// OSC52Clipboard returns a Clipboard that sets the terminal's clipboard by writing
+// an OSC 52 escape sequence to w. It works over SSH, provided the terminal supports it.
+func OSC52Clipboard(w io.Writer) Clipboard {
+	return func(text string) error {
+		_, err := fmt.Fprintf(w, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+		if err != nil {
+			return fmt.Errorf("failed to write to the terminal: %w", err)
+		}
+		return nil
+	}
+}
+
+// profileURL returns the web address of login's profile on host.
+func profileURL(host, login string) string {
+	return "https://" + host + "/" + login
+}
+
+// openCmd opens url and reports the outcome in the status bar.
+func openCmd(open Opener, url string) tea.Cmd {
+	return func() tea.Msg {
+		err := open(url)
+		switch {
+		case errors.Is(err, ErrNoBrowser):
+			return statusMsg("Open " + url + " in your browser")
+		case err != nil:
+			return statusMsg(fmt.Sprintf("Failed to open %s: %v", url, err))
+		}
+		return statusMsg("Opened " + url)
+	}
+}
+
+// copyCmd copies text to the clipboard and reports the outcome in the status bar.
+func copyCmd(clipboard Clipboard, text string) tea.Cmd {
+	return func() tea.Msg {
+		if err := clipboard(text); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to copy %s: %v", text, err))
+		}
+		return statusMsg("Copied " + text)
+	}
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"time"
+
+	"gh-mutual-follow/internal/audit"
+	"gh-mutual-follow/internal/config"
+	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/history"
+	"gh-mutual-follow/internal/store"
+)
+
+// Option configures the TUI model.
+type Option func(*tuiModel)
+
+// WithBulkConcurrency sets how many users a bulk action processes in parallel.
+func WithBulkConcurrency(n int) Option {
+	return func(m *tuiModel) { m.executor.Concurrency = n }
+}
+
+// WithBulkThrottle sets the minimum delay between two calls of a bulk action.
+func WithBulkThrottle(d time.Duration) Option {
+	return func(m *tuiModel) { m.executor.Throttle = d }
+}
+
+// WithDryRun marks the session as a dry run in the header. The client is expected
+// to be a github.DryRunClient.
+func WithDryRun() Option {
+	return func(m *tuiModel) { m.dryRun = true }
+}
+
+// WithAuditLog enables undo from the audit log the client writes to.
+func WithAuditLog(log *audit.Log) Option {
+	return func(m *tuiModel) { m.auditLog = log }
+}
+
+// WithConfirmation selects which actions ask for confirmation: single unfollows and bulk actions.
+func WithConfirmation(single, bulk bool) Option {
+	return func(m *tuiModel) {
+		m.confirmSingle = single
+		m.confirmBulk = bulk
+	}
+}
+
+// WithConfirmPreview sets how many affected logins a confirmation dialog lists.
+func WithConfirmPreview(n int) Option {
+	return func(m *tuiModel) { m.confirmPreview = n }
+}
+
+// WithOpener sets how profile URLs are opened. The default is BrowserOpener.
+func WithOpener(open Opener) Option {
+	return func(m *tuiModel) { m.opener = open }
+}
+
+// WithClipboard sets how logins and URLs are copied. The default writes OSC 52 to stderr.
+func WithClipboard(clipboard Clipboard) Option {
+	return func(m *tuiModel) { m.clipboard = clipboard }
+}
+
+// WithWebHost sets the host of profile URLs, for GitHub Enterprise Server.
+func WithWebHost(host string) Option {
+	return func(m *tuiModel) { m.webHost = host }
+}
+
+// WithHistory saves a snapshot of the relationships on every load and enables the Changes view.
+func WithHistory(log history.Store) Option {
+	return func(m *tuiModel) { m.history = log }
+}
+
+// WithCache lets an explicit refresh bypass the lists cached by client. client must
+// be the caching client wrapped by the one the model uses, if any.
+func WithCache(client *github.CachingClient) Option {
+	return func(m *tuiModel) { m.cache = client }
+}
+
+// WithStore starts from the relationships cached in s while they are refreshed, and
+// saves every load in s. The store also serves as the history of the Changes view.
+func WithStore(s store.Store) Option {
+	return func(m *tuiModel) {
+		m.store = s
+		m.history = s
+	}
+}
+
+// WithConfig starts from the allowlist and denylist of c, and saves the users pinned
+// and ignored from the TUI to f.
+func WithConfig(f *config.File, c config.Config) Option {
+	return func(m *tuiModel) {
+		m.config = f
+		*m.lists = c
+	}
+}
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// searchHeight is the number of lines of the search bar, when it is shown.
+const searchHeight = 1
+
+// searchBar filters the panes by a fuzzy query over the login and the known profile
+// fields of the users.
+type searchBar struct {
+	input     textinput.Model
+	focused   bool // keys go to the input
+	allPanes  bool // the query filters every pane instead of the active one only
+	paneIndex int  // the pane filtered when allPanes is false
+}
+
+// newSearchBar returns a focused, empty search bar for the pane at paneIndex.
+func newSearchBar(paneIndex int) *searchBar {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "login, name, company or location"
+	input.Focus()
+	return &searchBar{input: input, focused: true, paneIndex: paneIndex}
+}
+
+// query returns the trimmed search text.
+func (s *searchBar) query() string {
+	return strings.TrimSpace(s.input.Value())
+}
+
+// filters reports whether the search applies to the pane at index i.
+func (s *searchBar) filters(i int) bool {
+	return s != nil && s.query() != "" && (s.allPanes || s.paneIndex == i)
+}
+
+// match is how a user matches a query: the best score over its fields and the
+// matched character positions in the login and the name, for highlighting.
+type match struct {
+	score        int
+	loginMatches []int
+	nameMatches  []int
+}
+
+// matchUser fuzzy-matches query against the login, name, company and location of i.
+func matchUser(query string, i item) (match, bool) {
+	fields := []string{i.user.Login, i.user.Name, i.user.Company, i.user.Location}
+	var m match
+	found := false
+	for f, value := range fields {
+		if value == "" {
+			continue
+		}
+		matches := fuzzy.Find(query, []string{value})
+		if len(matches) == 0 {
+			continue
+		}
+		if !found || matches[0].Score > m.score {
+			m.score = matches[0].Score
+		}
+		found = true
+		switch f {
+		case 0:
+			m.loginMatches = matches[0].MatchedIndexes
+		case 1:
+			m.nameMatches = matches[0].MatchedIndexes
+		}
+	}
+	return m, found
+}
+
+// filterItems returns the items matching query, best matches first, with their
+// matched positions set for highlighting. profiles fill in the fields the list
+// items were loaded without.
+func filterItems(items []list.Item, query string, profiles profileCache) []list.Item {
+	type scored struct {
+		item  item
+		score int
+	}
+	var results []scored
+	for _, li := range items {
+		i := li.(item)
+		searched := i
+		if p := profiles[i.user.Login]; p != nil && !p.loading && p.err == nil {
+			searched.user = p.user
+		}
+		m, ok := matchUser(query, searched)
+		if !ok {
+			continue
+		}
+		i.loginMatches, i.nameMatches = m.loginMatches, m.nameMatches
+		if searched.user.Name != i.user.Name {
+			i.nameMatches = nil // the highlighted name is not the one displayed
+		}
+		results = append(results, scored{item: i, score: m.score})
+	}
+	sort.SliceStable(results, func(a, b int) bool { return results[a].score > results[b].score })
+
+	filtered := make([]list.Item, len(results))
+	for k, r := range results {
+		filtered[k] = r.item
+	}
+	return filtered
+}
+
+// applySearch shows in every pane the users matching the search, or all of them.
+func (m *tuiModel) applySearch() {
+	for i := range m.panes {
+		p := &m.panes[i]
+		if m.search.filters(i) {
+			p.list.SetItems(filterItems(p.items, m.search.query(), m.profiles))
+		} else {
+			p.list.SetItems(p.items)
+		}
+	}
+}
+
+// updateSearch handles a key press while the search bar has the focus. Enter keeps
+// the filter and returns to the lists, Esc clears it and tab switches between
+// filtering the active pane and all panes.
+func (m tuiModel) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+	case "esc":
+		m.search = nil
+		m.applySearch()
+		m.resizePanes()
+		return m, nil
+	case "enter":
+		m.search.focused = false
+		m.search.input.Blur()
+		if m.search.query() == "" {
+			m.search = nil
+			m.resizePanes()
+		}
+		return m, nil
+	case "tab":
+		m.search.allPanes = !m.search.allPanes
+		m.search.paneIndex = m.activePane
+		m.applySearch()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.search.input, cmd = m.search.input.Update(msg)
+	m.applySearch()
+	return m, tea.Batch(cmd, m.scheduleProfile())
+}
+
+// searchView renders the search bar with the number of matches.
+func (m tuiModel) searchView() string {
+	scope := "in " + m.panes[m.search.paneIndex].title
+	matches := len(m.panes[m.search.paneIndex].list.Items())
+	if m.search.allPanes {
+		scope = "in all panes"
+		matches = 0
+		for _, p := range m.panes {
+			matches += len(p.list.Items())
+		}
+	}
+	info := scope
+	if m.search.query() != "" {
+		info = fmt.Sprintf("%d matches %s", matches, scope)
+	}
+	if m.search.focused {
+		info += "   [tab] Scope   [enter] Done   [esc] Clear"
+	} else {
+		info += "   [/] Edit   [esc] Clear"
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		m.search.input.View(),
+		m.styles.StatusMessage.Render(info),
+	)
+}
@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gh-mutual-follow/internal/audit"
+	"gh-mutual-follow/internal/bulk"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// itemState is the progress of a single follow or unfollow, shown next to the user.
+type itemState int
+
+const (
+	itemPending itemState = iota + 1 // the call is in flight
+	itemDone                         // the call succeeded
+	itemFailed                       // the call failed and the move was rolled back
+)
+
+// itemStates holds the state of the users acted on since the last load, by login.
+// It is shared by the model and the list delegates of every pane.
+type itemStates map[string]itemState
+
+// forgetSettled forgets the done and failed states, keeping the pending ones.
+func (s itemStates) forgetSettled() {
+	for login, state := range s {
+		if state != itemPending {
+			delete(s, login)
+		}
+	}
+}
+
+// noPane is the destination of a user who leaves the panes: someone unfollowed
+// who does not follow back.
+const noPane = -1
+
+// singleMove is where a single action moves a user: from the pane they were acted
+// on in to the pane they belong to afterwards.
+type singleMove struct {
+	action bulk.Action
+	user   item
+	from   int
+	to     int
+}
+
+// singleDoneMsg reports the end of a single follow or unfollow.
+type singleDoneMsg struct {
+	move singleMove
+	err  error
+}
+
+// destination returns the pane a user acted on in pane from ends up in.
+func destination(action bulk.Action, from int) int {
+	switch {
+	case action == bulk.Follow:
+		return mutualPane
+	case from == mutualPane:
+		return followersPane // still follows us
+	default:
+		return noPane
+	}
+}
+
+// runSingle follows or unfollows the selected user of the active pane. The user is
+// moved to their new pane at once and marked pending; the move is rolled back if
+// the call fails. The lists are not reloaded.
+func (m tuiModel) runSingle(action bulk.Action, login string) (tea.Model, tea.Cmd) {
+	if m.states[login] == itemPending {
+		m.statusMessage = fmt.Sprintf("%s is already being updated", login)
+		return m, clearStatusMsg()
+	}
+	from := m.activePane
+	i := slices.IndexFunc(m.panes[from].items, isLogin(login))
+	if i < 0 {
+		return m, nil
+	}
+	mv := singleMove{action: action, user: m.panes[from].items[i].(item), from: from, to: destination(action, from)}
+	m.moveUser(mv.user, mv.from, mv.to)
+	m.states[login] = itemPending
+
+	// Single actions are not tied to the operation context: Esc cancels loads and
+	// bulk actions, and one single action must not cancel another.
+	ctx := audit.WithBatch(m.ctx, audit.NewBatchID())
+	client := m.client
+	return m, func() tea.Msg {
+		var err error
+		if action == bulk.Unfollow {
+			err = client.Unfollow(ctx, login)
+		} else {
+			err = client.Follow(ctx, login)
+		}
+		return singleDoneMsg{move: mv, err: err}
+	}
+}
+
+// updateSingle marks the user of a finished single action done, or rolls the move back.
+func (m tuiModel) updateSingle(msg singleDoneMsg) (tea.Model, tea.Cmd) {
+	mv := msg.move
+	login := mv.user.user.Login
+	if msg.err != nil {
+		m.moveUser(mv.user, mv.to, mv.from)
+		m.states[login] = itemFailed
+		m.statusMessage = fmt.Sprintf("Failed to %s %s: %v", mv.action, login, msg.err)
+		return m, nil
+	}
+	// A reload may have put the user back in a pane while the call was in flight.
+	if from := m.paneOf(login); from != mv.to {
+		u := mv.user
+		if from != noPane {
+			u = m.panes[from].items[slices.IndexFunc(m.panes[from].items, isLogin(login))].(item)
+		}
+		m.moveUser(u, from, mv.to)
+	}
+	m.states[login] = itemDone
+	if mv.action == bulk.Unfollow {
+		m.statusMessage = fmt.Sprintf("Unfollowed %s!", login)
+	} else {
+		m.statusMessage = fmt.Sprintf("Followed %s!", login)
+	}
+	return m, clearStatusMsg()
+}
+
+// paneOf returns the pane holding login, or noPane.
+func (m tuiModel) paneOf(login string) int {
+	for i := range m.panes {
+		if slices.ContainsFunc(m.panes[i].items, isLogin(login)) {
+			return i
+		}
+	}
+	return noPane
+}
+
+// isLogin returns a predicate matching the item of login.
+func isLogin(login string) func(list.Item) bool {
+	return func(li list.Item) bool { return li.(item).user.Login == login }
+}
+
+// moveUser takes u out of pane from and inserts it in pane to, in login order,
+// unless a reload already put it there. Either can be noPane. Each pane keeps its
+// cursor on the same user, or at the same position when that user left.
+func (m *tuiModel) moveUser(u item, from, to int) {
+	cursors := make([]string, len(m.panes))
+	indexes := make([]int, len(m.panes))
+	for i := range m.panes {
+		indexes[i] = m.panes[i].list.Index()
+		if li, ok := m.panes[i].list.SelectedItem().(item); ok {
+			cursors[i] = li.user.Login
+		}
+	}
+
+	login := u.user.Login
+	if from != noPane {
+		p := &m.panes[from]
+		p.items = slices.DeleteFunc(slices.Clone(p.items), isLogin(login))
+		delete(p.selected, login)
+	}
+	if to != noPane {
+		p := &m.panes[to]
+		i, found := slices.BinarySearchFunc(p.items, login, func(li list.Item, login string) int {
+			return strings.Compare(li.(item).user.Login, login)
+		})
+		if !found {
+			u.loginMatches, u.nameMatches = nil, nil
+			p.items = slices.Insert(slices.Clone(p.items), i, list.Item(u))
+		}
+	}
+
+	m.applySearch()
+	for i := range m.panes {
+		l := &m.panes[i].list
+		items := l.Items()
+		if k := slices.IndexFunc(items, func(li list.Item) bool { return li.(item).user.Login == cursors[i] }); k >= 0 {
+			l.Select(k)
+		} else if len(items) > 0 {
+			l.Select(min(indexes[i], len(items)-1))
+		}
+	}
+}
@@ -4,16 +4,28 @@ import "github.com/charmbracelet/lipgloss"
 
 // TUIStyles holds the styles for the TUI.
 type TUIStyles struct {
-	Header        lipgloss.Style
-	Pane          lipgloss.Style
-	FocusedPane   lipgloss.Style
-	HelpStyle     lipgloss.Style
-	CursorStyle   lipgloss.Style
-	SelectedStyle lipgloss.Style
-	NoItemsStyle  lipgloss.Style
-	LoadingStyle  lipgloss.Style
-	ErrorStyle    lipgloss.Style
-	StatusMessage lipgloss.Style
+	Header         lipgloss.Style
+	Pane           lipgloss.Style
+	FocusedPane    lipgloss.Style
+	HelpStyle      lipgloss.Style
+	CursorStyle    lipgloss.Style
+	SelectedStyle  lipgloss.Style
+	NoItemsStyle   lipgloss.Style
+	LoadingStyle   lipgloss.Style
+	ErrorStyle     lipgloss.Style
+	StatusMessage  lipgloss.Style
+	NameStyle      lipgloss.Style
+	DetailTitle    lipgloss.Style
+	DetailLabel    lipgloss.Style
+	RateLimitPause lipgloss.Style
+	DryRunBadge    lipgloss.Style
+	CheckedStyle   lipgloss.Style
+	MatchStyle     lipgloss.Style
+	PendingStyle   lipgloss.Style
+	DoneStyle      lipgloss.Style
+	FailedStyle    lipgloss.Style
+	PinnedStyle    lipgloss.Style
+	IgnoredStyle   lipgloss.Style
 }
 
 func defaultStyles() *TUIStyles {
@@ -28,14 +40,12 @@ func defaultStyles() *TUIStyles {
 	s.Pane = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("#874BFD")).
-		Padding(1).
-		Height(16)
+		Padding(1)
 
 	s.FocusedPane = lipgloss.NewStyle().
 		Border(lipgloss.ThickBorder()).
 		BorderForeground(lipgloss.Color("#7D56F4")).
-		Padding(1).
-		Height(16)
+		Padding(1)
 
 	s.HelpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).PaddingLeft(1).PaddingRight(1)
 	s.CursorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
@@ -44,6 +54,18 @@ func defaultStyles() *TUIStyles {
 	s.LoadingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Bold(true)
 	s.ErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Bold(true)
 	s.StatusMessage = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).PaddingLeft(1)
+	s.NameStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	s.DetailTitle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
+	s.DetailLabel = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	s.RateLimitPause = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500")).Bold(true).PaddingLeft(1)
+	s.DryRunBadge = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#FFA500")).PaddingLeft(1).PaddingRight(1)
+	s.CheckedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD700")).Bold(true)
+	s.MatchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF79C6")).Underline(true)
+	s.PendingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	s.DoneStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
+	s.FailedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Bold(true)
+	s.PinnedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00BFFF"))
+	s.IgnoredStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F5F"))
 
 	return s
 }
@@ -1,12 +1,30 @@
 package tui
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
 	"testing"
-	// "gh-mutual-follow/internal/github" // Kept for future tests
+	"time"
+
+	"gh-mutual-follow/internal/audit"
+	"gh-mutual-follow/internal/bulk"
+	"gh-mutual-follow/internal/config"
+	"gh-mutual-follow/internal/github"
+	"gh-mutual-follow/internal/history"
+	"gh-mutual-follow/internal/store"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,39 +33,73 @@ type mockGitHubClient struct {
 	GetUserFunc      func() (string, error)
 	GetFollowingFunc func(user string) ([]string, error)
 	GetFollowersFunc func(user string) ([]string, error)
+	GetProfileFunc   func(login string) (github.User, error)
+	LastActivityFunc func(login string) (time.Time, error)
 	UnfollowFunc     func(user string) error
 	FollowFunc       func(user string) error
 }
 
-func (m *mockGitHubClient) GetUser() (string, error) {
+func (m *mockGitHubClient) GetUser(ctx context.Context) (string, error) {
 	if m.GetUserFunc != nil {
 		return m.GetUserFunc()
 	}
 	return "", errors.New("GetUserFunc not implemented")
 }
 
-func (m *mockGitHubClient) GetFollowing(user string) ([]string, error) {
+func (m *mockGitHubClient) GetFollowing(ctx context.Context, user string) ([]string, error) {
 	if m.GetFollowingFunc != nil {
 		return m.GetFollowingFunc(user)
 	}
 	return nil, errors.New("GetFollowingFunc not implemented")
 }
 
-func (m *mockGitHubClient) GetFollowers(user string) ([]string, error) {
+func (m *mockGitHubClient) GetFollowers(ctx context.Context, user string) ([]string, error) {
 	if m.GetFollowersFunc != nil {
 		return m.GetFollowersFunc(user)
 	}
 	return nil, errors.New("GetFollowersFunc not implemented")
 }
 
-func (m *mockGitHubClient) Unfollow(user string) error {
+func (m *mockGitHubClient) GetFollowingUsers(ctx context.Context, user string) ([]github.User, error) {
+	logins, err := m.GetFollowing(ctx, user)
+	return loginsToUsers(logins), err
+}
+
+func (m *mockGitHubClient) GetFollowersUsers(ctx context.Context, user string) ([]github.User, error) {
+	logins, err := m.GetFollowers(ctx, user)
+	return loginsToUsers(logins), err
+}
+
+func (m *mockGitHubClient) GetUserProfile(ctx context.Context, login string) (github.User, error) {
+	if m.GetProfileFunc != nil {
+		return m.GetProfileFunc(login)
+	}
+	return github.User{}, errors.New("GetProfileFunc not implemented")
+}
+
+func (m *mockGitHubClient) GetLastActivity(ctx context.Context, login string) (time.Time, error) {
+	if m.LastActivityFunc != nil {
+		return m.LastActivityFunc(login)
+	}
+	return time.Time{}, errors.New("LastActivityFunc not implemented")
+}
+
+func loginsToUsers(logins []string) []github.User {
+	users := make([]github.User, len(logins))
+	for i, l := range logins {
+		users[i] = github.User{Login: l}
+	}
+	return users
+}
+
+func (m *mockGitHubClient) Unfollow(ctx context.Context, user string) error {
 	if m.UnfollowFunc != nil {
 		return m.UnfollowFunc(user)
 	}
 	return errors.New("UnfollowFunc not implemented")
 }
 
-func (m *mockGitHubClient) Follow(user string) error {
+func (m *mockGitHubClient) Follow(ctx context.Context, user string) error {
 	if m.FollowFunc != nil {
 		return m.FollowFunc(user)
 	}
@@ -61,8 +113,10 @@ func TestNewModel(t *testing.T) {
 	assert.NotNil(t, m.client)
 	assert.True(t, m.loading)
 	assert.Equal(t, followingPane, m.activePane)
-	assert.Empty(t, m.followingList.Items())
-	assert.Empty(t, m.followersList.Items())
+	assert.Len(t, m.panes, 3)
+	for _, p := range m.panes {
+		assert.Empty(t, p.list.Items())
+	}
 }
 
 func TestUpdate_TabKey(t *testing.T) {
@@ -79,18 +133,27 @@ func TestUpdate_TabKey(t *testing.T) {
 	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
 	model, ok = m.(tuiModel)
 	assert.True(t, ok)
-	assert.Equal(t, followingPane, model.activePane)
+	assert.Equal(t, mutualPane, model.activePane)
+
+	// Third tab wraps around
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, followingPane, m.(tuiModel).activePane)
+
+	// Shift+tab goes backwards
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	assert.Equal(t, mutualPane, m.(tuiModel).activePane)
 }
 
 func TestUpdate_DataLoaded(t *testing.T) {
 	var m tea.Model = NewModel()
 	// m.loading is already true from NewModel()
 
-	items := []list.Item{item("test1"), item("test2")}
+	items := []list.Item{newItem("test1"), newItem("test2")}
 	msg := dataLoadedMsg{
 		username:      "testuser",
 		onlyFollowing: items,
 		onlyFollowers: items,
+		mutual:        items[:1],
 	}
 
 	m, _ = m.Update(msg)
@@ -100,8 +163,9 @@ func TestUpdate_DataLoaded(t *testing.T) {
 	assert.False(t, updatedModel.loading)
 	assert.Nil(t, updatedModel.err)
 	assert.Equal(t, "testuser", updatedModel.username)
-	assert.Equal(t, items, updatedModel.followingList.Items())
-	assert.Equal(t, items, updatedModel.followersList.Items())
+	assert.Equal(t, items, updatedModel.panes[followingPane].list.Items())
+	assert.Equal(t, items, updatedModel.panes[followersPane].list.Items())
+	assert.Equal(t, items[:1], updatedModel.panes[mutualPane].list.Items())
 }
 
 func TestUpdate_Error(t *testing.T) {
@@ -118,3 +182,1173 @@ func TestUpdate_Error(t *testing.T) {
 	assert.False(t, updatedModel.loading)
 	assert.Equal(t, expectedErr, updatedModel.err)
 }
+
+func TestLoadDataCmd_CarriesProfiles(t *testing.T) {
+	client := &mockGitHubClient{
+		GetUserFunc:      func() (string, error) { return "me", nil },
+		GetFollowingFunc: func(user string) ([]string, error) { return []string{"alice", "bob"}, nil },
+		GetFollowersFunc: func(user string) ([]string, error) { return []string{"bob", "carol"}, nil },
+	}
+
+	msg, ok := loadDataCmd(context.Background(), client)().(dataLoadedMsg)
+	assert.True(t, ok)
+	assert.Equal(t, "me", msg.username)
+	assert.Equal(t, []list.Item{item{user: github.User{Login: "alice"}}}, msg.onlyFollowing)
+	assert.Equal(t, []list.Item{item{user: github.User{Login: "carol"}}}, msg.onlyFollowers)
+	assert.Equal(t, []list.Item{item{user: github.User{Login: "bob"}}}, msg.mutual)
+}
+
+func TestUpdate_DetailView(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{})
+	m, _ = m.Update(dataLoadedMsg{
+		username:      "me",
+		onlyFollowing: []list.Item{item{user: github.User{Login: "alice", Name: "Alice Liddell", Location: "Wonderland"}}},
+	})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	model := m.(tuiModel)
+	assert.True(t, model.showDetail)
+	assert.Contains(t, model.View(), "Alice Liddell")
+	assert.Contains(t, model.View(), "Wonderland")
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	assert.False(t, m.(tuiModel).showDetail)
+}
+
+func TestUpdate_DetailFetchesProfileLazily(t *testing.T) {
+	fetches := 0
+	client := &mockGitHubClient{
+		GetProfileFunc: func(login string) (github.User, error) {
+			fetches++
+			return github.User{Login: login, Name: "Bob Builder", Company: "ACME", Followers: 7}, nil
+		},
+		LastActivityFunc: func(login string) (time.Time, error) {
+			return time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local), nil
+		},
+	}
+	var m tea.Model = NewModelWithClient(client)
+	m, _ = m.Update(dataLoadedMsg{
+		username:      "me",
+		onlyFollowing: []list.Item{item{user: github.User{Login: "bob"}}},
+		mutual:        []list.Item{item{user: github.User{Login: "carol"}}},
+	})
+
+	// Nothing is fetched until the panel is open and the cursor rests.
+	assert.Equal(t, 0, fetches)
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	assert.NotNil(t, cmd)
+	assert.Contains(t, m.View(), "Loading profile...")
+	assert.Contains(t, m.View(), "does not follow you")
+
+	m, cmd = m.Update(profileRestMsg{login: "bob"})
+	assert.NotNil(t, cmd)
+	m, _ = m.Update(cmd())
+	assert.Equal(t, 1, fetches)
+	view := m.View()
+	assert.Contains(t, view, "Bob Builder")
+	assert.Contains(t, view, "ACME")
+	assert.Contains(t, view, "2024-05-01 12:00")
+	assert.NotContains(t, view, "Loading profile...")
+
+	// The profile is cached: resting on the user again does not fetch it again.
+	m, cmd = m.Update(profileRestMsg{login: "bob"})
+	assert.Nil(t, cmd)
+	assert.Nil(t, m.(tuiModel).scheduleProfile())
+	assert.Equal(t, 1, fetches)
+
+	// A rest on a user the cursor has already left is ignored.
+	m, cmd = m.Update(profileRestMsg{login: "carol"})
+	assert.Nil(t, cmd)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	assert.Contains(t, m.View(), "follows you")
+	assert.NotContains(t, m.View(), "does not follow you")
+}
+
+func TestUpdate_DetailProfileError(t *testing.T) {
+	failing := true
+	client := &mockGitHubClient{
+		GetProfileFunc: func(login string) (github.User, error) {
+			if failing {
+				return github.User{}, errors.New("not found")
+			}
+			return github.User{Login: login, Bio: "Back again"}, nil
+		},
+		LastActivityFunc: func(login string) (time.Time, error) { return time.Time{}, nil },
+	}
+	var m tea.Model = NewModelWithClient(client)
+	m, _ = m.Update(dataLoadedMsg{
+		username:      "me",
+		onlyFollowing: []list.Item{item{user: github.User{Login: "ghost"}}, newItem("bob")},
+	})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m, cmd := m.Update(profileRestMsg{login: "ghost"})
+	m, _ = m.Update(cmd())
+	assert.Contains(t, m.View(), "Profile unavailable: not found")
+
+	// The failure is not cached: coming back to the user fetches the profile again.
+	failing = false
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m, cmd = m.Update(profileRestMsg{login: "ghost"})
+	assert.NotNil(t, cmd)
+	m, _ = m.Update(cmd())
+	assert.Contains(t, m.View(), "Back again")
+}
+
+func TestView_DetailLayout(t *testing.T) {
+	load := func(width int) tea.Model {
+		var m tea.Model = NewModelWithClient(&mockGitHubClient{})
+		m, _ = m.Update(tea.WindowSizeMsg{Width: width, Height: 40})
+		m, _ = m.Update(dataLoadedMsg{
+			username:      "me",
+			onlyFollowing: []list.Item{item{user: github.User{Login: "alice", Name: "Alice Liddell"}}},
+		})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+		return m
+	}
+
+	// Wide terminals show the panel as a column next to the panes.
+	wide := load(160).View()
+	assert.Contains(t, wide, "Following")
+	assert.Contains(t, wide, "Alice Liddell")
+
+	// Narrow terminals show it instead of the panes.
+	narrow := load(80).View()
+	assert.NotContains(t, narrow, "Followers")
+	assert.Contains(t, narrow, "Alice Liddell")
+}
+
+func TestUpdate_OpenAndCopy(t *testing.T) {
+	var opened []string
+	var clipboard bytes.Buffer
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{},
+		WithOpener(func(url string) error {
+			opened = append(opened, url)
+			return nil
+		}),
+		WithClipboard(OSC52Clipboard(&clipboard)),
+		WithWebHost("github.example.com"),
+	)
+	m, _ = m.Update(dataLoadedMsg{
+		username:      "me",
+		onlyFollowing: []list.Item{item{user: github.User{Login: "alice"}}},
+	})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m, _ = m.Update(cmd())
+	assert.Equal(t, []string{"https://github.example.com/alice"}, opened)
+	assert.Equal(t, "Opened https://github.example.com/alice", m.(tuiModel).statusMessage)
+
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m, _ = m.Update(cmd())
+	assert.Equal(t, "\x1b]52;c;YWxpY2U=\a", clipboard.String()) // base64 of "alice"
+	assert.Equal(t, "Copied alice", m.(tuiModel).statusMessage)
+
+	clipboard.Reset()
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Y")})
+	m, _ = m.Update(cmd())
+	want := base64.StdEncoding.EncodeToString([]byte("https://github.example.com/alice"))
+	assert.Equal(t, "\x1b]52;c;"+want+"\a", clipboard.String())
+}
+
+func TestUpdate_OpenWithoutBrowser(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{},
+		WithOpener(func(url string) error { return ErrNoBrowser }),
+	)
+	m, _ = m.Update(dataLoadedMsg{
+		username:      "me",
+		onlyFollowers: []list.Item{item{user: github.User{Login: "bob"}}},
+	})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m, _ = m.Update(cmd())
+	assert.Contains(t, m.View(), "Open https://github.com/bob in your browser")
+}
+
+func TestBrowserOpener(t *testing.T) {
+	t.Setenv("BROWSER", "gh-mutual-follow-no-such-browser")
+	err := BrowserOpener("https://github.com/alice")
+	assert.ErrorIs(t, err, ErrNoBrowser)
+}
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestView_LayoutGolden(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	var following []list.Item
+	for i := range 30 {
+		following = append(following, newItem(fmt.Sprintf("user%02d", i)))
+	}
+	tests := []struct {
+		name          string
+		width, height int
+		detail        bool
+		search        string
+	}{
+		{"stacked_60x24", 60, 24, false, ""},
+		{"stacked_80x40", 80, 40, false, ""},
+		{"columns_100x30", 100, 30, false, ""},
+		{"columns_160x40", 160, 40, false, ""},
+		{"detail_column_160x40", 160, 40, true, ""},
+		{"detail_overlay_100x30", 100, 30, true, ""},
+		{"search_100x30", 100, 30, false, "/user1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m tea.Model = NewModelWithClient(&mockGitHubClient{})
+			m, _ = m.Update(tea.WindowSizeMsg{Width: tt.width, Height: tt.height})
+			m, _ = m.Update(dataLoadedMsg{
+				username:      "me",
+				onlyFollowing: following,
+				onlyFollowers: []list.Item{newItem("alice"), newItem("bob")},
+				mutual:        []list.Item{newItem("carol")},
+			})
+			if tt.detail {
+				m = typeKeys(m, "d")
+			}
+			if tt.search != "" {
+				m = typeKeys(m, tt.search)
+				m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			}
+			view := m.View()
+			assert.LessOrEqual(t, lipgloss.Width(view), tt.width, "the view is wider than the terminal")
+			assert.LessOrEqual(t, lipgloss.Height(view), tt.height, "the view is taller than the terminal")
+
+			golden := filepath.Join("testdata", "layout_"+tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(view), 0o644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			assert.Equal(t, string(expected), view)
+		})
+	}
+}
+
+func TestUpdate_Search(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{})
+	m, _ = m.Update(dataLoadedMsg{
+		username: "me",
+		onlyFollowing: []list.Item{
+			newItem("alice"),
+			item{user: github.User{Login: "bob", Name: "Alicia Builder"}},
+			newItem("carol"),
+		},
+		onlyFollowers: []list.Item{newItem("alina"), newItem("dave")},
+	})
+
+	m = typeKeys(m, "/ali")
+	model := m.(tuiModel)
+	following := model.panes[followingPane].list.Items()
+	assert.Len(t, following, 2, "alice by login, bob by name")
+	assert.Equal(t, "alice", following[0].(item).user.Login, "a login match at the start scores best")
+	assert.Equal(t, []int{0, 1, 2}, following[0].(item).loginMatches)
+	assert.Equal(t, []int{0, 1, 2}, following[1].(item).nameMatches)
+	assert.Len(t, model.panes[followersPane].list.Items(), 2, "other panes are not filtered")
+	assert.Contains(t, m.View(), "2 matches in Following")
+
+	// Typing goes to the search bar, not to the key bindings.
+	assert.False(t, model.showDetail)
+	assert.Equal(t, "ali", model.search.query())
+
+	// Tab searches every pane.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model = m.(tuiModel)
+	assert.Len(t, model.panes[followersPane].list.Items(), 1)
+	assert.Contains(t, m.View(), "3 matches in all panes")
+
+	// Enter keeps the filter and hands the keys back to the lists.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = typeKeys(m, "d")
+	model = m.(tuiModel)
+	assert.True(t, model.showDetail)
+	assert.Len(t, model.panes[followingPane].list.Items(), 2)
+
+	// Esc clears the search.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = m.(tuiModel)
+	assert.Nil(t, model.search)
+	assert.Len(t, model.panes[followingPane].list.Items(), 3)
+	assert.Len(t, model.panes[followersPane].list.Items(), 2)
+}
+
+func TestUpdate_SearchUsesCachedProfiles(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{})
+	m, _ = m.Update(dataLoadedMsg{
+		username:      "me",
+		onlyFollowing: []list.Item{newItem("alice"), newItem("bob")},
+	})
+	m, _ = m.Update(profileLoadedMsg{login: "bob", user: github.User{Login: "bob", Company: "Wonderland Inc", Location: "Oxford"}})
+
+	m = typeKeys(m, "/oxford")
+	items := m.(tuiModel).panes[followingPane].list.Items()
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, "bob", items[0].(item).user.Login)
+	}
+}
+
+func TestUpdate_BulkActionOnSearchResults(t *testing.T) {
+	var mu sync.Mutex
+	var unfollowed []string
+	client := &mockGitHubClient{UnfollowFunc: func(user string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		unfollowed = append(unfollowed, user)
+		return nil
+	}}
+	var m tea.Model = NewModelWithClient(client, WithBulkThrottle(0), WithConfirmation(false, false))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice"), newItem("alison"), newItem("bob")}})
+
+	m = typeKeys(m, "/ali")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	drainBulk(t, m, cmd)
+	assert.ElementsMatch(t, []string{"alice", "alison"}, unfollowed)
+}
+
+func TestUpdate_Changes(t *testing.T) {
+	log := history.NewLog(filepath.Join(t.TempDir(), "snapshots.jsonl"))
+	old := history.NewSnapshot("me", []string{"alice", "carol"}, []string{"bob", "dave"})
+	old.Time = time.Now().Add(-3 * 24 * time.Hour)
+	if err := log.Append(old); err != nil {
+		t.Fatalf("failed to append snapshot: %v", err)
+	}
+
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{}, WithHistory(log))
+	m, cmd := m.Update(dataLoadedMsg{
+		username:      "me",
+		onlyFollowing: []list.Item{newItem("alice"), newItem("erin")},
+		onlyFollowers: []list.Item{newItem("frank")},
+		mutual:        []list.Item{newItem("bob")},
+	})
+	drain(m, cmd)
+	snapshots, err := log.Snapshots("me")
+	assert.NoError(t, err)
+	assert.Len(t, snapshots, 2, "the load is saved as a snapshot")
+
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	m, _ = m.Update(cmd())
+	view := m.View()
+	assert.Contains(t, view, "Changes in the last 7 days")
+	assert.Contains(t, view, "New followers (1)")
+	assert.Contains(t, view, "frank")
+	assert.Contains(t, view, "Lost followers (1)")
+	assert.Contains(t, view, "dave")
+	assert.Contains(t, view, "Started following (2)")
+	assert.Contains(t, view, "Stopped following (1)")
+
+	// The history does not go back 30 days.
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = m.Update(cmd())
+	assert.Contains(t, m.View(), "Changes in the last 30 days")
+	assert.Contains(t, m.View(), "History only goes back to")
+
+	// The state 24 hours ago is still the one of the old snapshot.
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = m.Update(cmd())
+	assert.Contains(t, m.View(), "Changes in the last 24 hours")
+	assert.Contains(t, m.View(), "frank")
+	assert.NotContains(t, m.View(), "History only goes back to")
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, m.(tuiModel).changes)
+}
+
+func TestUpdate_ChangesWithoutHistory(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{})
+	m, _ = m.Update(dataLoadedMsg{username: "me"})
+	m = typeKeys(m, "C")
+	assert.Contains(t, m.View(), "Changes are not available")
+}
+
+func TestUpdate_StartsFromCachedData(t *testing.T) {
+	s := store.NewMemory()
+	assert.NoError(t, s.Append(history.NewSnapshot("me", []string{"alice", "bob"}, []string{"bob"})))
+	assert.NoError(t, s.SaveUsers([]github.User{{Login: "alice", Name: "Alice Liddell"}}))
+
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{}, WithStore(s))
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	m, _ = m.Update(loadCachedCmd(s)())
+	model := m.(tuiModel)
+	assert.False(t, model.loading)
+	assert.True(t, model.refreshing)
+	assert.Equal(t, []list.Item{item{user: github.User{Login: "alice", Name: "Alice Liddell"}}}, model.panes[followingPane].list.Items())
+	assert.Equal(t, []list.Item{item{user: github.User{Login: "bob"}}}, model.panes[mutualPane].list.Items())
+	assert.Contains(t, m.View(), "Refreshing...")
+
+	m, cmd := m.Update(dataLoadedMsg{
+		username:      "me",
+		onlyFollowing: []list.Item{newItem("alice")},
+		onlyFollowers: []list.Item{newItem("carol")},
+		mutual:        []list.Item{newItem("bob")},
+	})
+	drain(m, cmd)
+	model = m.(tuiModel)
+	assert.False(t, model.refreshing)
+	assert.NotContains(t, m.View(), "Refreshing...")
+	latest, _, err := s.Latest("me")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bob", "carol"}, latest.Followers, "the fresh load is saved")
+	users, err := s.Users([]string{"carol"})
+	assert.NoError(t, err)
+	assert.Contains(t, users, "carol")
+
+	// A cache load arriving after the fresh lists is ignored.
+	m, _ = m.Update(loadCachedCmd(s)())
+	assert.Equal(t, []list.Item{newItem("carol")}, m.(tuiModel).panes[followersPane].list.Items())
+}
+
+func TestUpdate_RefreshFailureKeepsCachedData(t *testing.T) {
+	s := store.NewMemory()
+	assert.NoError(t, s.Append(history.NewSnapshot("me", []string{"alice"}, nil)))
+
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{}, WithStore(s))
+	m, _ = m.Update(loadCachedCmd(s)())
+	m, _ = m.Update(errorMsg{err: errors.New("offline")})
+	model := m.(tuiModel)
+	assert.Nil(t, model.err)
+	assert.False(t, model.refreshing)
+	assert.Equal(t, "Refresh failed: offline", model.statusMessage)
+	assert.Len(t, model.panes[followingPane].list.Items(), 1)
+}
+
+func TestLoadCachedCmd_EmptyStore(t *testing.T) {
+	assert.Nil(t, loadCachedCmd(store.NewMemory())())
+}
+
+func TestUpdate_SingleActionServedFromCache(t *testing.T) {
+	var fetches int
+	following := []string{"alice", "bob"}
+	client := &mockGitHubClient{
+		GetUserFunc:      func() (string, error) { return "me", nil },
+		GetFollowingFunc: func(string) ([]string, error) { fetches++; return following, nil },
+		GetFollowersFunc: func(string) ([]string, error) { return nil, nil },
+		UnfollowFunc:     func(string) error { return nil },
+	}
+	cache := github.NewCachingClient(client, time.Hour)
+	var m tea.Model = NewModelWithClient(cache, WithCache(cache), WithConfirmation(false, false))
+	for _, msg := range collect(m.Init()) {
+		m, _ = m.Update(msg)
+	}
+	assert.Equal(t, 1, fetches)
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+	assert.Equal(t, 1, fetches, "a single action does not fetch the lists again")
+	assert.Equal(t, []list.Item{newItem("bob")}, m.(tuiModel).panes[followingPane].list.Items())
+
+	// An explicit refresh fetches the lists again.
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+	assert.Equal(t, 2, fetches)
+	assert.Len(t, m.(tuiModel).panes[followingPane].list.Items(), 2)
+}
+
+// contextClient is a mockGitHubClient whose GetUser fails once ctx is cancelled.
+type contextClient struct {
+	mockGitHubClient
+}
+
+func (c *contextClient) GetUser(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return c.mockGitHubClient.GetUser(ctx)
+}
+
+func TestUpdate_RevalidationAfterEsc(t *testing.T) {
+	client := &contextClient{mockGitHubClient{
+		GetUserFunc:      func() (string, error) { return "me", nil },
+		GetFollowingFunc: func(user string) ([]string, error) { return []string{"alice", "bob"}, nil },
+		GetFollowersFunc: func(user string) ([]string, error) { return []string{"bob"}, nil },
+	}}
+	var m tea.Model = NewModelWithClient(client)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc}) // cancels the first load
+	m, _ = m.Update(errorMsg{context.Canceled})
+	assert.Error(t, m.(tuiModel).opCtx.Err())
+
+	// Esc cancelled the operation context; the background reload must not use it.
+	m, cmd := m.Update(CacheRevalidatedMsg{})
+	msg := cmd()
+	assert.IsType(t, dataLoadedMsg{}, msg)
+	m, _ = m.Update(msg)
+	assert.Len(t, m.(tuiModel).panes[mutualPane].items, 1)
+}
+
+func TestUpdate_SingleActionMovesUserOptimistically(t *testing.T) {
+	release := make(chan error)
+	client := &mockGitHubClient{FollowFunc: func(string) error { return <-release }}
+	var m tea.Model = NewModelWithClient(client)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	m, _ = m.Update(dataLoadedMsg{
+		username:      "me",
+		onlyFollowers: []list.Item{newItem("alice"), newItem("bob"), newItem("carol")},
+		mutual:        []list.Item{newItem("amy"), newItem("dave")},
+	})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab}) // Followers
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	// bob moves to Mutual at once, marked pending, and the cursor stays in place.
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model := m.(tuiModel)
+	assert.False(t, model.loading)
+	assert.Equal(t, []list.Item{newItem("alice"), newItem("carol")}, model.panes[followersPane].list.Items())
+	assert.Equal(t, 1, model.panes[followersPane].list.Index())
+	assert.Equal(t, []list.Item{newItem("amy"), newItem("bob"), newItem("dave")}, model.panes[mutualPane].list.Items())
+	assert.Equal(t, itemPending, model.states["bob"])
+	assert.Contains(t, m.View(), "bob …")
+
+	// A failure puts bob back where he was.
+	done := make(chan tea.Msg)
+	go func() { done <- cmd() }()
+	release <- errors.New("blocked")
+	m, _ = m.Update(<-done)
+	model = m.(tuiModel)
+	assert.Nil(t, model.err)
+	assert.Equal(t, []list.Item{newItem("alice"), newItem("bob"), newItem("carol")}, model.panes[followersPane].list.Items())
+	assert.Equal(t, []list.Item{newItem("amy"), newItem("dave")}, model.panes[mutualPane].list.Items())
+	assert.Equal(t, itemFailed, model.states["bob"])
+	assert.Contains(t, m.View(), "bob ✗")
+	assert.Contains(t, m.View(), "Failed to follow bob: blocked")
+
+	// Following carol succeeds.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	go func() { done <- cmd() }()
+	release <- nil
+	m, _ = m.Update(<-done)
+	model = m.(tuiModel)
+	assert.Equal(t, []list.Item{newItem("alice"), newItem("bob")}, model.panes[followersPane].list.Items())
+	assert.Equal(t, itemDone, model.states["carol"])
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab}) // Mutual
+	assert.Contains(t, m.View(), "carol ✓")
+
+	// A reload forgets the settled states.
+	m, _ = m.Update(dataLoadedMsg{username: "me"})
+	assert.Empty(t, m.(tuiModel).states)
+}
+
+func TestUpdate_SingleActionAfterStaleReload(t *testing.T) {
+	release := make(chan error)
+	client := &mockGitHubClient{FollowFunc: func(string) error { return <-release }}
+	var m tea.Model = NewModelWithClient(client)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	loaded := dataLoadedMsg{
+		username:      "me",
+		onlyFollowers: []list.Item{newItem("alice"), newItem("bob")},
+		mutual:        []list.Item{newItem("amy")},
+	}
+	m, _ = m.Update(loaded)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab}) // Followers
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// A reload fetched before the follow landed puts bob back among the followers.
+	m, _ = m.Update(loaded)
+	model := m.(tuiModel)
+	assert.Equal(t, []list.Item{newItem("alice"), newItem("bob")}, model.panes[followersPane].list.Items())
+	assert.Equal(t, itemPending, model.states["bob"])
+
+	done := make(chan tea.Msg)
+	go func() { done <- cmd() }()
+	release <- nil
+	m, _ = m.Update(<-done)
+	model = m.(tuiModel)
+	assert.Equal(t, []list.Item{newItem("alice")}, model.panes[followersPane].list.Items())
+	assert.Equal(t, []list.Item{newItem("amy"), newItem("bob")}, model.panes[mutualPane].list.Items())
+	assert.Equal(t, itemDone, model.states["bob"])
+}
+
+func TestUpdate_UnfollowRemovesUserFromPanes(t *testing.T) {
+	client := &mockGitHubClient{UnfollowFunc: func(string) error { return nil }}
+	var m tea.Model = NewModelWithClient(client, WithConfirmation(false, false))
+	m, _ = m.Update(dataLoadedMsg{
+		username:      "me",
+		onlyFollowing: []list.Item{newItem("alice"), newItem("bob")},
+		mutual:        []list.Item{newItem("carol")},
+	})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+	model := m.(tuiModel)
+	assert.Equal(t, []list.Item{newItem("bob")}, model.panes[followingPane].list.Items())
+	assert.Equal(t, "Unfollowed alice!", model.statusMessage)
+
+	// Unfollowing from Mutual leaves a follower.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyShiftTab}) // Mutual
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+	model = m.(tuiModel)
+	assert.Empty(t, model.panes[mutualPane].list.Items())
+	assert.Equal(t, []list.Item{newItem("carol")}, model.panes[followersPane].list.Items())
+}
+
+// drain runs cmd and the commands it batches or sequences, without feeding the messages back to m.
+func drain(m tea.Model, cmd tea.Cmd) {
+	collect(cmd)
+}
+
+// collect runs cmd and the commands it batches or sequences, in order, and returns their messages.
+func collect(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	// tea.Sequence returns an unexported slice of commands.
+	if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice && v.Type().Elem() == reflect.TypeOf(tea.Cmd(nil)) {
+		var msgs []tea.Msg
+		for i := range v.Len() {
+			msgs = append(msgs, collect(v.Index(i).Interface().(tea.Cmd))...)
+		}
+		return msgs
+	}
+	if msg == nil {
+		return nil
+	}
+	return []tea.Msg{msg}
+}
+
+// drainBulk feeds the messages of a running bulk action back into the model until it finishes.
+func drainBulk(t *testing.T, m tea.Model, cmd tea.Cmd) tea.Model {
+	t.Helper()
+	for cmd != nil {
+		msg := cmd()
+		if msg == nil {
+			break
+		}
+		m, cmd = m.Update(msg)
+		if _, done := msg.(bulkDoneMsg); done {
+			break
+		}
+	}
+	return m
+}
+
+func TestUpdate_EscCancelsBulkAction(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	client := &mockGitHubClient{
+		UnfollowFunc: func(user string) error {
+			close(started)
+			<-release
+			return nil
+		},
+	}
+	var m tea.Model = NewModelWithClient(client, WithBulkConcurrency(1), WithBulkThrottle(0), WithConfirmation(false, false))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice"), newItem("bob")}})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	model := m.(tuiModel)
+	assert.True(t, model.isBulkActionInProgress)
+	opCtx := model.opCtx
+
+	<-started // alice is in flight
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.ErrorIs(t, opCtx.Err(), context.Canceled)
+	assert.False(t, m.(tuiModel).quitting)
+	close(release)
+
+	model = drainBulk(t, m, cmd).(tuiModel)
+	assert.False(t, model.isBulkActionInProgress)
+	assert.NotNil(t, model.bulkReport)
+	assert.Equal(t, 1, model.bulkReport.Count(bulk.Succeeded))
+	assert.Equal(t, 1, model.bulkReport.Count(bulk.Skipped))
+	assert.Equal(t, "bob", model.bulkReport.Results[1].Login)
+}
+
+func TestUpdate_StatusDuringBulkActionKeepsItRunning(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	client := &mockGitHubClient{
+		UnfollowFunc: func(user string) error {
+			close(started)
+			<-release
+			return nil
+		},
+	}
+	var m tea.Model = NewModelWithClient(client, WithBulkConcurrency(1), WithBulkThrottle(0), WithConfirmation(false, false))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice")}})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	opCtx := m.(tuiModel).opCtx
+	<-started
+
+	// A leftover status tick must not unlock the UI, or r would cancel the bulk action.
+	m, _ = m.Update(statusMsg(""))
+	assert.True(t, m.(tuiModel).isBulkActionInProgress)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	assert.NoError(t, opCtx.Err())
+	close(release)
+
+	model := drainBulk(t, m, cmd).(tuiModel)
+	assert.False(t, model.isBulkActionInProgress)
+	assert.Equal(t, 1, model.bulkReport.Count(bulk.Succeeded))
+}
+
+func TestUpdate_BulkActionReportsFailuresAndRetries(t *testing.T) {
+	attempts := map[string]int{}
+	client := &mockGitHubClient{
+		FollowFunc: func(user string) error {
+			attempts[user]++
+			if user == "bob" && attempts[user] == 1 {
+				return errors.New("HTTP 404: Not Found")
+			}
+			return nil
+		},
+	}
+	var m tea.Model = NewModelWithClient(client, WithBulkConcurrency(1), WithBulkThrottle(0), WithConfirmation(false, false))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowers: []list.Item{newItem("alice"), newItem("bob"), newItem("carol")}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = drainBulk(t, m, cmd)
+	model := m.(tuiModel)
+	assert.NotNil(t, model.bulkReport)
+	assert.Equal(t, 2, model.bulkReport.Count(bulk.Succeeded))
+	assert.Equal(t, []string{"bob"}, model.bulkReport.Failed())
+	assert.Contains(t, m.View(), "bob: HTTP 404: Not Found")
+	assert.Contains(t, m.View(), "[R] Retry failed")
+
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	m = drainBulk(t, m, cmd)
+	model = m.(tuiModel)
+	assert.Equal(t, map[string]int{"alice": 1, "bob": 2, "carol": 1}, attempts)
+	assert.Equal(t, 1, model.bulkReport.Count(bulk.Succeeded))
+	assert.Empty(t, model.bulkReport.Failed())
+
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = m.(tuiModel)
+	assert.Nil(t, model.bulkReport)
+	assert.True(t, model.loading, "closing the summary reloads the lists")
+	assert.NotNil(t, cmd)
+}
+
+func TestUpdate_QuitCancelsContext(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{})
+	ctx := m.(tuiModel).ctx
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	assert.True(t, m.(tuiModel).quitting)
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.Equal(t, tea.Quit(), cmd())
+}
+
+func TestUpdate_CancelledLoadIsNotAnError(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = m.Update(errorMsg{err: context.Canceled})
+	model := m.(tuiModel)
+	assert.False(t, model.loading)
+	assert.Nil(t, model.err)
+	assert.Equal(t, "Cancelled", model.statusMessage)
+}
+
+func TestUpdate_RateLimit(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{})
+	m, _ = m.Update(dataLoadedMsg{username: "me"})
+
+	reset := time.Now().Add(time.Hour)
+	m, cmd := m.Update(RateLimitMsg{RateLimit: github.RateLimit{Limit: 5000, Remaining: 4999, Reset: reset}})
+	assert.Nil(t, cmd)
+	assert.Contains(t, m.View(), "API quota 4999/5000")
+
+	m, cmd = m.Update(RateLimitMsg{Pause: time.Minute, Until: time.Now().Add(time.Minute)})
+	assert.NotNil(t, cmd, "a pause should start the countdown ticker")
+	assert.Contains(t, m.View(), "Rate limited, resuming in")
+	assert.Contains(t, m.View(), "API quota 4999/5000", "a pause without headers keeps the last known quota")
+
+	model := m.(tuiModel)
+	model.pauseUntil = time.Now().Add(-time.Second)
+	m, cmd = model.Update(pauseTickMsg{})
+	assert.Nil(t, cmd, "the ticker stops once the pause is over")
+	assert.NotContains(t, m.View(), "Rate limited")
+}
+
+func TestUpdate_BulkProgressBar(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{}, WithBulkThrottle(0))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowers: []list.Item{newItem("alice")}})
+	model := m.(tuiModel)
+	model.isBulkActionInProgress = true
+	model.bulkAction = bulk.Follow
+
+	m, _ = model.Update(bulkProgressMsg{progress: bulk.Progress{
+		Result:  bulk.Result{Login: "alice", Status: bulk.Succeeded},
+		Done:    1,
+		Total:   4,
+		Elapsed: 2 * time.Second,
+	}})
+	view := m.View()
+	assert.Contains(t, view, "25%")
+	assert.Contains(t, view, "Bulk follow 1/4")
+	assert.Contains(t, view, "ETA 6s")
+	assert.Contains(t, view, "alice succeeded")
+}
+
+func TestView_DryRunHeader(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{}, WithDryRun())
+	m, _ = m.Update(dataLoadedMsg{username: "me"})
+	assert.Contains(t, m.View(), "DRY RUN")
+
+	m = NewModelWithClient(&mockGitHubClient{})
+	m, _ = m.Update(dataLoadedMsg{username: "me"})
+	assert.NotContains(t, m.View(), "DRY RUN")
+}
+
+func TestUpdate_UndoReversesLastBulkAction(t *testing.T) {
+	var followed []string
+	var mu sync.Mutex
+	mock := &mockGitHubClient{
+		GetUserFunc:  func() (string, error) { return "me", nil },
+		UnfollowFunc: func(user string) error { return nil },
+		FollowFunc: func(user string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			followed = append(followed, user)
+			return nil
+		},
+	}
+	log := audit.NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	client := audit.NewClient(mock, log, "s1")
+	var m tea.Model = NewModelWithClient(client, WithAuditLog(log), WithBulkThrottle(0), WithConfirmation(false, false))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice"), newItem("bob")}})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = drainBulk(t, m, cmd)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	assert.NotNil(t, cmd)
+	m, cmd = m.Update(cmd())
+	m = drainBulk(t, m, cmd)
+	model := m.(tuiModel)
+	assert.Equal(t, bulk.Follow, model.bulkReport.Action)
+	assert.ElementsMatch(t, []string{"alice", "bob"}, followed)
+
+	// The undo itself is not undoable, so the stack is now empty.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	assert.Equal(t, statusMsg("Nothing to undo"), cmd())
+}
+
+func TestUpdate_UndoIgnoresListsAndRetriesFailures(t *testing.T) {
+	var followed []string
+	var mu sync.Mutex
+	failBob := true
+	mock := &mockGitHubClient{
+		GetUserFunc:  func() (string, error) { return "me", nil },
+		UnfollowFunc: func(user string) error { return nil },
+		FollowFunc: func(user string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if user == "bob" && failBob {
+				return errors.New("HTTP 500")
+			}
+			followed = append(followed, user)
+			return nil
+		},
+	}
+	log := audit.NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	client := audit.NewClient(mock, log, "s1")
+	// alice is on the denylist, which must not stop the undo from following her back.
+	var m tea.Model = NewModelWithClient(client, WithAuditLog(log), WithBulkThrottle(0), WithConfirmation(false, false),
+		WithConfig(nil, config.Config{Deny: []string{"alice"}}))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice"), newItem("bob")}})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = drainBulk(t, m, cmd)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	m, cmd = m.Update(cmd())
+	m = drainBulk(t, m, cmd)
+	assert.Equal(t, []string{"alice"}, followed)
+
+	// bob failed, so undoing again retries him alone.
+	failBob = false
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	undo, ok := cmd().(undoMsg)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"bob"}, undo.logins)
+	m, cmd = m.Update(undo)
+	drainBulk(t, m, cmd)
+	assert.Equal(t, []string{"alice", "bob"}, followed)
+}
+
+func TestUpdate_UndoWithoutAuditLog(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{})
+	m, _ = m.Update(dataLoadedMsg{username: "me"})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	assert.Contains(t, m.View(), "Undo is not available")
+}
+
+func typeKeys(m tea.Model, s string) tea.Model {
+	for _, r := range s {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+func TestUpdate_ConfirmBulkAction(t *testing.T) {
+	var mu sync.Mutex
+	var followed []string
+	client := &mockGitHubClient{FollowFunc: func(user string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		followed = append(followed, user)
+		return nil
+	}}
+	var m tea.Model = NewModelWithClient(client, WithBulkThrottle(0), WithConfirmPreview(2))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowers: []list.Item{newItem("alice"), newItem("bob"), newItem("carol")}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	assert.Nil(t, cmd, "a bulk action waits for confirmation")
+	view := m.View()
+	assert.Contains(t, view, "Follow 3 users?")
+	assert.Contains(t, view, "alice")
+	assert.Contains(t, view, "bob")
+	assert.NotContains(t, view, "carol")
+	assert.Contains(t, view, "... and 1 more")
+
+	// Enter does nothing until the count or "yes" is typed.
+	m = typeKeys(m, "2")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cmd)
+	assert.NotNil(t, m.(tuiModel).confirm)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = typeKeys(m, "3")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = drainBulk(t, m, cmd)
+	assert.Nil(t, m.(tuiModel).confirm)
+	assert.ElementsMatch(t, []string{"alice", "bob", "carol"}, followed)
+}
+
+func TestUpdate_PinnedAndIgnoredUsersAreLeftOutOfBulkActions(t *testing.T) {
+	var mu sync.Mutex
+	var followed, unfollowed []string
+	client := &mockGitHubClient{
+		FollowFunc: func(user string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			followed = append(followed, user)
+			return nil
+		},
+		UnfollowFunc: func(user string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			unfollowed = append(unfollowed, user)
+			return nil
+		},
+	}
+	file := config.NewFile(filepath.Join(t.TempDir(), "config.yaml"))
+	var m tea.Model = NewModelWithClient(client, WithBulkThrottle(0), WithConfig(file, config.Config{Deny: []string{"carol"}}))
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	m, _ = m.Update(dataLoadedMsg{
+		username:      "me",
+		onlyFollowing: []list.Item{newItem("alice"), newItem("bob")},
+		onlyFollowers: []list.Item{newItem("carol"), newItem("dave")},
+	})
+
+	// Pin alice, the user under the cursor, and save the config.
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m, _ = m.Update(cmd())
+	assert.Contains(t, m.View(), "Pinned alice")
+	assert.Contains(t, m.View(), "alice [pinned]")
+	assert.Contains(t, m.View(), "carol [ignored]")
+	saved, err := file.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, config.Config{Allow: []string{"alice"}, Deny: []string{"carol"}}, saved)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	view := m.View()
+	assert.Contains(t, view, "Unfollow bob?")
+	assert.Contains(t, view, "1 pinned users left out")
+	m = typeKeys(m, "1")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = drainBulk(t, m, cmd)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, []string{"bob"}, unfollowed)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = typeKeys(m, "yes")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = drainBulk(t, m, cmd)
+	assert.Equal(t, []string{"dave"}, followed)
+
+	// Unignoring carol saves the config again.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	m, _ = m.Update(cmd())
+	assert.Equal(t, "Unignored carol", m.(tuiModel).statusMessage)
+	saved, err = file.Load()
+	assert.NoError(t, err)
+	assert.Empty(t, saved.Deny)
+}
+
+func TestUpdate_BulkActionOnProtectedUsersOnly(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{}, WithConfig(nil, config.Config{Allow: []string{"alice"}}))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice")}})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	model := m.(tuiModel)
+	assert.Nil(t, model.confirm)
+	assert.False(t, model.isBulkActionInProgress)
+	assert.Equal(t, "Nothing to unfollow: every user is pinned", model.statusMessage)
+}
+
+func TestUpdate_ConfirmBulkActionWithYes(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{UnfollowFunc: func(string) error { return nil }}, WithBulkThrottle(0))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice"), newItem("bob")}})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = typeKeys(m, "yes")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = drainBulk(t, m, cmd)
+	assert.Equal(t, 2, m.(tuiModel).bulkReport.Count(bulk.Succeeded))
+}
+
+func TestUpdate_ConfirmSingleUnfollow(t *testing.T) {
+	unfollowed := 0
+	client := &mockGitHubClient{UnfollowFunc: func(string) error { unfollowed++; return nil }}
+	var m tea.Model = NewModelWithClient(client)
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice")}})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cmd)
+	assert.Contains(t, m.View(), "Unfollow alice?")
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	assert.Nil(t, m.(tuiModel).confirm)
+	assert.Contains(t, m.View(), "Cancelled")
+	assert.Equal(t, 0, unfollowed)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	assert.False(t, m.(tuiModel).loading)
+	assert.Equal(t, itemPending, m.(tuiModel).states["alice"])
+	assert.NotNil(t, cmd)
+	drain(m, cmd)
+	assert.Equal(t, 1, unfollowed)
+}
+
+func TestUpdate_ConfirmationDisabled(t *testing.T) {
+	var m tea.Model = NewModelWithClient(&mockGitHubClient{}, WithConfirmation(false, false))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice")}})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, m.(tuiModel).confirm)
+	assert.NotNil(t, cmd)
+}
+
+func TestUpdate_MultiSelect(t *testing.T) {
+	var mu sync.Mutex
+	var unfollowed []string
+	client := &mockGitHubClient{UnfollowFunc: func(user string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		unfollowed = append(unfollowed, user)
+		return nil
+	}}
+	var m tea.Model = NewModelWithClient(client, WithBulkThrottle(0), WithConfirmation(false, false))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice"), newItem("bob"), newItem("carol")}})
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	m, _ = m.Update(space)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(space)
+	assert.Contains(t, m.View(), "Following (2 selected)")
+	assert.Contains(t, m.View(), "[x]")
+	assert.ElementsMatch(t, []string{"alice", "carol"}, keys(m.(tuiModel).panes[followingPane].selected))
+
+	m = typeKeys(m, "*")
+	assert.ElementsMatch(t, []string{"bob"}, keys(m.(tuiModel).panes[followingPane].selected))
+	m = typeKeys(m, "x")
+	assert.Empty(t, m.(tuiModel).panes[followingPane].selected)
+	assert.NotContains(t, m.View(), "selected)")
+	m = typeKeys(m, "v")
+	assert.Len(t, m.(tuiModel).panes[followingPane].selected, 3)
+	m = typeKeys(m, "x")
+
+	// The bulk action only applies to the selection.
+	m, _ = m.Update(space)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m, _ = m.Update(space)
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = drainBulk(t, m, cmd)
+	assert.ElementsMatch(t, []string{"bob", "carol"}, unfollowed)
+
+	// Reloading drops the users that left the pane from the selection.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice")}})
+	assert.Empty(t, m.(tuiModel).panes[followingPane].selected)
+}
+
+func TestUpdate_BulkActionOnSelectionHiddenBySearch(t *testing.T) {
+	var mu sync.Mutex
+	var unfollowed []string
+	client := &mockGitHubClient{UnfollowFunc: func(user string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		unfollowed = append(unfollowed, user)
+		return nil
+	}}
+	var m tea.Model = NewModelWithClient(client, WithBulkThrottle(0), WithConfirmation(false, false))
+	m, _ = m.Update(dataLoadedMsg{username: "me", onlyFollowing: []list.Item{newItem("alice"), newItem("bob"), newItem("carol")}})
+
+	// Check bob, then search for someone else: the selection is hidden.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m = typeKeys(m, "/carol")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	drainBulk(t, m, cmd)
+	assert.Equal(t, []string{"bob"}, unfollowed, "only the checked user is acted on")
+}
+
+func keys(s selection) []string {
+	var logins []string
+	for login := range s {
+		logins = append(logins, login)
+	}
+	return logins
+}
+
+func TestUpdate_UnfollowFromMutualPane(t *testing.T) {
+	var unfollowed []string
+	client := &mockGitHubClient{UnfollowFunc: func(user string) error {
+		unfollowed = append(unfollowed, user)
+		return nil
+	}}
+	var m tea.Model = NewModelWithClient(client, WithBulkThrottle(0))
+	m, _ = m.Update(dataLoadedMsg{username: "me", mutual: []list.Item{newItem("bob")}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	assert.Equal(t, mutualPane, m.(tuiModel).activePane)
+	assert.Contains(t, m.View(), "Mutual")
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Contains(t, m.View(), "Unfollow bob?")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	collect(cmd)
+	assert.Equal(t, []string{"bob"}, unfollowed)
+}
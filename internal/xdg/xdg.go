@@ -0,0 +1,39 @@
+// Package xdg locates the files of gh-mutual-follow in the XDG base directories.
+package xdg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// appDir is the directory of gh-mutual-follow inside a base directory.
+const appDir = "gh-mutual-follow"
+
+// StatePath returns the location of the state file name:
+// $XDG_STATE_HOME/gh-mutual-follow/<name>, or ~/.local/state/... when unset.
+func StatePath(name string) (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate the state directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, appDir, name), nil
+}
+
+// ConfigPath returns the location of the configuration file name:
+// $XDG_CONFIG_HOME/gh-mutual-follow/<name>, or ~/.config/... when unset.
+func ConfigPath(name string) (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate the config directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, appDir, name), nil
+}
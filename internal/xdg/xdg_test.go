@@ -0,0 +1,48 @@
+package xdg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStatePath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+	path, err := StatePath("audit.jsonl")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := filepath.Join("/tmp/state", "gh-mutual-follow", "audit.jsonl"); path != want {
+		t.Errorf("expected %s, got %s", want, path)
+	}
+
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", "/home/me")
+	path, err = StatePath("store.db")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := filepath.Join("/home/me", ".local", "state", "gh-mutual-follow", "store.db"); path != want {
+		t.Errorf("expected %s, got %s", want, path)
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/config")
+	path, err := ConfigPath("config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := filepath.Join("/tmp/config", "gh-mutual-follow", "config.yaml"); path != want {
+		t.Errorf("expected %s, got %s", want, path)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "/home/me")
+	path, err = ConfigPath("config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := filepath.Join("/home/me", ".config", "gh-mutual-follow", "config.yaml"); path != want {
+		t.Errorf("expected %s, got %s", want, path)
+	}
+}
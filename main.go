@@ -1,18 +1,11 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
-	"gh-mutual-follow/internal/tui"
-	tea "github.com/charmbracelet/bubbletea"
+	"gh-mutual-follow/internal/cli"
 )
 
 func main() {
-	m := tui.NewModel()
-	p := tea.NewProgram(m)
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Alas, there's been an error: %v", err)
-		os.Exit(1)
-	}
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
 }